@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"testing"
+)
+
+// 벤치마크는 input.txt 의 상태마다 하나씩 돈다.
+//
+//	go test -run '^$' -bench . [-benchtime 1s] [-cpuprofile cpu.out]
+
+// benchState 는 벤치마크용으로 읽어둔 상태 하나 (전역변수들의 사본)
+type benchState struct {
+	name                string
+	width, height, myID int
+	board               [][]int
+	players             []Player
+	bombs               []Bomb
+	items               []Item
+	me                  Player
+}
+
+func (s *benchState) restore() {
+	width, height, myID = s.width, s.height, s.myID
+	setLayout(width, height)
+	board, players, items, me = s.board, s.players, s.items, s.me
+	bombs = append(bombs[:0], s.bombs...)
+}
+
+// loadStates 는 input.txt 처럼 여러 게임이 이어 붙은 파일에서 상태들을 읽는다.
+func loadStates(path string) ([]benchState, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	g := game{newParser(f), io.Discard, mainBot{}}
+	if err := g.init(); err != nil {
+		return nil, err
+	}
+	var states []benchState
+	for g.read() {
+		states = append(states, benchState{
+			name:    fmt.Sprintf("%s:%d", path, g.in.turnLine),
+			width:   width,
+			height:  height,
+			myID:    myID,
+			board:   board,
+			players: players,
+			bombs:   append([]Bomb(nil), bombs...),
+			items:   items,
+			me:      me,
+		})
+	}
+	return states, nil
+}
+
+// benchStates 는 input.txt 의 상태마다 f 를 sub-benchmark 로 돌린다.
+// f 가 bombs 를 바꿀 수 있어서 반복마다 상태를 되돌린다.
+func benchStates(b *testing.B, f func()) {
+	lg.out = io.Discard
+	states, err := loadStates("input.txt")
+	if err != nil {
+		b.Fatal(err)
+	}
+	// 벤치마크에서는 시간 제한 없이 끝까지 계산한다.
+	clock = turnClock{}
+	for i := range states {
+		s := &states[i]
+		b.Run(s.name, func(b *testing.B) {
+			b.ReportAllocs()
+			for n := 0; n < b.N; n++ {
+				s.restore()
+				f()
+			}
+		})
+	}
+}
+
+func visitAll(x, y, d, x0, y0 int, bombs []Bomb, items []Item) bool {
+	return false
+}
+
+func BenchmarkBFS(b *testing.B) {
+	benchStates(b, func() { bfs(me.Pos.at(0), bombs, items, visitAll) })
+}
+
+func BenchmarkBFSReference(b *testing.B) {
+	benchStates(b, func() { bfsReference(me.Pos.at(0), bombs, items, visitAll) })
+}
+
+func BenchmarkSyncBombs(b *testing.B) {
+	benchStates(b, func() { syncBombs(bombs, board, items) })
+}
+
+func BenchmarkCanDropBomb(b *testing.B) {
+	benchStates(b, func() { me.canDropBomb(me.Pos.at(0), bombs) })
+}
+
+func BenchmarkRound(b *testing.B) {
+	benchStates(b, func() { think() })
+}
+
+// BenchmarkDuel 은 1:1 인 상태에서만 탐색한다. 시간 제한이 없어서 duelMaxDepth 까지 본다.
+func BenchmarkDuel(b *testing.B) {
+	benchStates(b, func() { duel(action{pos: me.Pos}) })
+}
+
+// bfsReference 는 arena 를 쓰기 전의 map 기반 bfs. 비교와 벤치마크용으로 남겨둔다.
+func bfsReference(pos Pos3, bombs []Bomb, items []Item, visit func(x, y, d, x0, y0 int, bombs []Bomb, items []Item) bool) ([]Pos3, bool) {
+	back := map[Pos3]Pos3{}
+	getPath := func(next Pos3) []Pos3 {
+		sz := next.Z - pos.Z
+		path := make([]Pos3, sz)
+		sz--
+		path[sz] = next
+		for pos != back[next] {
+			next = back[next]
+			sz--
+			path[sz] = next
+		}
+		return path
+	}
+
+	layer := []Pos3{pos}
+	if visit(pos.X, pos.Y, pos.Z, pos.X, pos.Y, bombs, items) {
+		return nil, true
+	}
+
+	dxs := []int{0, 0, 1, 0, -1}
+	dys := []int{0, 1, 0, -1, 0}
+	d := pos.Z
+	for i := 0; len(layer) > 0 && i <= width; i++ {
+
+		bombs = removeOld(bombs, d)
+		// item도 없어져야 하고..
+		// 상자도 없어져야 하고..
+		// 그러면 아이템도 생겨야 하고..
+
+		var newLayer = SetPos3{}
+		for _, p := range layer {
+			for k := 0; k < 5; k++ {
+				dx := dxs[k]
+				dy := dys[k]
+				next := Pos3{p.X + dx, p.Y + dy, p.Z + 1}
+				if canGo(next, bombs) && !newLayer.has(next) {
+					newLayer.add(next)
+					back[next] = p
+					if visit(next.X, next.Y, next.Z, p.X, p.Y, bombs, items) {
+						return getPath(next), true
+					}
+				}
+			}
+		}
+		layer = newLayer.toSlice()
+	}
+
+	return nil, false
+}
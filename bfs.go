@@ -0,0 +1,149 @@
+package main
+
+// bfsDepth 는 한번의 bfs 가 내려갈 수 있는 최대 턴 수 (arena 의 시간 축)
+const bfsDepth = 32
+
+// bfsEngine 은 bfs 한번에 필요한 버퍼를 미리 잡아두고 재사용한다.
+// (x, y, t) 마다 방문 표시를 gen 값으로 남기기 때문에
+// 다음 bfs 를 할 때 배열을 비울 필요가 없다.
+type bfsEngine struct {
+	gen   uint32
+	stamp [bfsDepth][bbBits]uint32
+	from  [bfsDepth][bbBits]uint8
+
+	danger [bfsDepth]bitboard
+	placed [bfsDepth]bitboard // t 턴부터 막히는 칸 (앞으로 놓을 폭탄)
+	bombs  []Bomb
+	path   [bfsDepth]Pos3
+}
+
+// bfs 안에서 (canDropBomb, canEscapeFrom 처럼) 또 bfs 를 부르기 때문에
+// 엔진은 깊이별로 하나씩 쓴다.
+var bfsPool []*bfsEngine
+var bfsInUse int
+
+func acquireBFS() *bfsEngine {
+	if bfsInUse == len(bfsPool) {
+		bfsPool = append(bfsPool, &bfsEngine{bombs: make([]Bomb, 0, 32)})
+	}
+	e := bfsPool[bfsInUse]
+	bfsInUse++
+	return e
+}
+
+func releaseBFS() {
+	bfsInUse--
+}
+
+func (e *bfsEngine) next() uint32 {
+	e.gen++
+	if e.gen == 0 {
+		e.stamp = [bfsDepth][bbBits]uint32{}
+		e.gen = 1
+	}
+	return e.gen
+}
+
+// bfs 는 시간 축(d)을 고려하고,
+// d는 항상 증가하기 때문에,
+// 어차피 방문한 곳을 또 방문할 일이 없다.
+// 즉, 현 상태의 bombs를 보고
+// 안전한 경로로 bfs를 진행해보자.
+//
+// 돌려주는 경로는 엔진 버퍼라서 다음 bfs 전까지만 유효하다.
+func bfs(pos Pos3, bombs []Bomb, items []Item, visit func(x, y, d, x0, y0 int, bombs []Bomb, items []Item) bool) ([]Pos3, bool) {
+	if visit(pos.X, pos.Y, pos.Z, pos.X, pos.Y, bombs, items) {
+		return nil, true
+	}
+
+	e := acquireBFS()
+	defer releaseBFS()
+	gen := e.next()
+
+	// 이미 터진 폭탄은 뺀다.
+	// item도 없어져야 하고..
+	// 상자도 없어져야 하고..
+	// 그러면 아이템도 생겨야 하고..
+	e.bombs = e.bombs[:0]
+	var blocked bitboard
+	for t := range e.danger {
+		e.danger[t] = bitboard{}
+		e.placed[t] = bitboard{}
+	}
+	for _, b := range bombs {
+		if b.CountDown <= pos.Z {
+			continue
+		}
+		e.bombs = append(e.bombs, b)
+		if t := b.Placed - pos.Z; t > 0 {
+			if t < bfsDepth {
+				e.placed[t].set(geo.index(b.Pos))
+			}
+		} else {
+			blocked.set(geo.index(b.Pos))
+		}
+		// 폭탄에 아무도 안 죽는 리그면 피할 필요가 없다.
+		if !rules.Kills {
+			continue
+		}
+		if t := b.CountDown - 1 - pos.Z; t < bfsDepth {
+			e.danger[t] = e.danger[t].or(geo.cross(b.Pos, b.Range))
+		}
+	}
+
+	var floor bitboard
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if board[y][x] == cellFloor {
+				floor.set(geo.index(Pos{x, y}))
+			}
+		}
+	}
+	floor = floor.andNot(blocked)
+
+	stride := int(geo.stride)
+	steps := [5]int{0, 1, stride, -1, -stride}
+
+	var layer bitboard
+	layer.set(geo.index(pos.Pos()))
+	e.stamp[0][geo.index(pos.Pos())] = gen
+
+	for t := 0; !layer.isZero() && t <= rules.searchDepth() && t+1 < bfsDepth; t++ {
+		floor = floor.andNot(e.placed[t+1])
+		open := floor.andNot(e.danger[t+1])
+		var newLayer bitboard
+		for !layer.isZero() {
+			// 시간이 다 됐으면 못 찾은 걸로 한다.
+			if clock.expired() {
+				return nil, false
+			}
+			i := layer.lowest()
+			layer.unset(i)
+			for _, step := range steps {
+				j := i + step
+				if j < 0 || j >= bbBits || !open.has(j) || e.stamp[t+1][j] == gen {
+					continue
+				}
+				e.stamp[t+1][j] = gen
+				e.from[t+1][j] = uint8(i)
+				newLayer.set(j)
+				p, p0 := geo.pos(j), geo.pos(i)
+				if visit(p.X, p.Y, pos.Z+t+1, p0.X, p0.Y, e.bombs, items) {
+					return e.getPath(pos.Z, t+1, j), true
+				}
+			}
+		}
+		layer = newLayer
+	}
+
+	return nil, false
+}
+
+func (e *bfsEngine) getPath(z0, t, i int) []Pos3 {
+	path := e.path[:t]
+	for ; t > 0; t-- {
+		path[t-1] = geo.pos(i).at(z0 + t)
+		i = int(e.from[t][i])
+	}
+	return path
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// randomState 는 randomBoard 위에 나(0 번)와 폭탄 몇 개를 놓는다.
+func randomState(rng *rand.Rand) {
+	randomBoard(rng, 13, 11)
+	var floor []Pos
+	for y, row := range board {
+		for x, c := range row {
+			if p := (Pos{x, y}); c == cellFloor && !isItem(p) {
+				floor = append(floor, p)
+			}
+		}
+	}
+	rng.Shuffle(len(floor), func(i, j int) { floor[i], floor[j] = floor[j], floor[i] })
+	myID = 0
+	me = Player{Pos: floor[0], ID: myID, Bombs: 1, Range: 3}
+	players = []Player{me}
+	for _, p := range floor[1 : 1+rng.Intn(6)] {
+		bombs = append(bombs, Bomb{Pos: p, Owner: myID, CountDown: 1 + rng.Intn(8), Range: 2 + rng.Intn(4)})
+	}
+	syncBombs(bombs, board, items)
+}
+
+// TestBFSMatchesReference 는 arena bfs 가 map 기반 bfsReference 와
+// 같은 순서로 같은 곳을 방문하고, 멈췄을 때 같은 경로를 돌려주는지 본다.
+func TestBFSMatchesReference(t *testing.T) {
+	type visitFunc = func(x, y, d, x0, y0 int, bombs []Bomb, items []Item) bool
+	tests := []struct {
+		name string
+		stop func(start Pos) func(x, y, d int) bool
+	}{
+		{"all", func(Pos) func(x, y, d int) bool {
+			return func(x, y, d int) bool { return false }
+		}},
+		{"far", func(start Pos) func(x, y, d int) bool {
+			return func(x, y, d int) bool { return abs(start.X-x)+abs(start.Y-y) >= 3 }
+		}},
+		{"late", func(start Pos) func(x, y, d int) bool {
+			return func(x, y, d int) bool { return d >= 6 && (Pos{x, y}) != start }
+		}},
+	}
+	for seed := int64(1); seed <= 40; seed++ {
+		randomState(rand.New(rand.NewSource(seed)))
+		for _, tt := range tests {
+			var visited [2][]Pos3
+			record := func(k int) visitFunc {
+				stop := tt.stop(me.Pos)
+				return func(x, y, d, x0, y0 int, bombs []Bomb, items []Item) bool {
+					visited[k] = append(visited[k], Pos3{x, y, d})
+					return stop(x, y, d)
+				}
+			}
+			wantPath, wantOK := bfsReference(me.Pos.at(0), bombs, items, record(0))
+			want := fmt.Sprint(wantPath, wantOK)
+			gotPath, gotOK := bfs(me.Pos.at(0), bombs, items, record(1))
+			got := fmt.Sprint(gotPath, gotOK)
+			if fmt.Sprint(visited[0]) != fmt.Sprint(visited[1]) {
+				t.Fatalf("seed %d %s: visit order differs\nbfs:       %v\nreference: %v", seed, tt.name, visited[1], visited[0])
+			}
+			if got != want {
+				t.Fatalf("seed %d %s: bfs returned %s, reference %s", seed, tt.name, got, want)
+			}
+		}
+	}
+}
@@ -0,0 +1,242 @@
+package main
+
+import "math/bits"
+
+// bitboard 는 칸 하나에 비트 하나를 쓰는 보드다.
+// 칸 번호는 열 우선(x*stride + y)이고, 각 열 끝에 경계 비트를 하나 둔다.
+// 그래서 위/아래는 1, 좌/우는 stride 만큼 shift 하면 되고,
+// 경계 비트만 지우면 옆 열로 넘어가는 일이 없다.
+// 기본 13x11 보드는 (11+1)*13 = 156 비트라서 192 비트에 들어간다.
+type bitboard [bbWords]uint64
+
+const (
+	bbWords = 3
+	bbBits  = bbWords * 64
+)
+
+// bbHorizon 은 턴별 폭발 맵을 몇 턴까지 계산할지
+const bbHorizon = 32
+
+func (b *bitboard) set(i int) {
+	b[i>>6] |= 1 << uint(i&63)
+}
+
+func (b *bitboard) unset(i int) {
+	b[i>>6] &^= 1 << uint(i&63)
+}
+
+func (b bitboard) has(i int) bool {
+	return b[i>>6]&(1<<uint(i&63)) != 0
+}
+
+func (b bitboard) or(o bitboard) bitboard {
+	return bitboard{b[0] | o[0], b[1] | o[1], b[2] | o[2]}
+}
+
+func (b bitboard) and(o bitboard) bitboard {
+	return bitboard{b[0] & o[0], b[1] & o[1], b[2] & o[2]}
+}
+
+func (b bitboard) andNot(o bitboard) bitboard {
+	return bitboard{b[0] &^ o[0], b[1] &^ o[1], b[2] &^ o[2]}
+}
+
+func (b bitboard) isZero() bool {
+	return b[0]|b[1]|b[2] == 0
+}
+
+func (b bitboard) count() int {
+	return bits.OnesCount64(b[0]) + bits.OnesCount64(b[1]) + bits.OnesCount64(b[2])
+}
+
+// shl 은 n(<64) 비트만큼 인덱스가 커지는 쪽으로 민다.
+func (b bitboard) shl(n uint) bitboard {
+	return bitboard{
+		b[0] << n,
+		b[1]<<n | b[0]>>(64-n),
+		b[2]<<n | b[1]>>(64-n),
+	}
+}
+
+// shr 은 n(<64) 비트만큼 인덱스가 작아지는 쪽으로 민다.
+func (b bitboard) shr(n uint) bitboard {
+	return bitboard{
+		b[0]>>n | b[1]<<(64-n),
+		b[1]>>n | b[2]<<(64-n),
+		b[2] >> n,
+	}
+}
+
+// lowest 는 가장 작은 인덱스를 돌려준다. 비어있으면 -1
+func (b bitboard) lowest() int {
+	for w := 0; w < bbWords; w++ {
+		if b[w] != 0 {
+			return w*64 + bits.TrailingZeros64(b[w])
+		}
+	}
+	return -1
+}
+
+// layout 은 보드 크기에 따른 비트 배치
+type layout struct {
+	w, h   int
+	stride uint
+	valid  bitboard
+}
+
+var geo layout
+
+func setLayout(w, h int) {
+	geo = layout{w: w, h: h, stride: uint(h + 1)}
+	if (h+1)*w > bbBits {
+		panic("bitboard: board too large")
+	}
+	for x := 0; x < w; x++ {
+		for y := 0; y < h; y++ {
+			geo.valid.set(geo.index(Pos{x, y}))
+		}
+	}
+}
+
+func (l *layout) index(p Pos) int {
+	return p.X*int(l.stride) + p.Y
+}
+
+func (l *layout) pos(i int) Pos {
+	return Pos{i / int(l.stride), i % int(l.stride)}
+}
+
+func (l *layout) north(b bitboard) bitboard { return b.shr(1).and(l.valid) }
+func (l *layout) south(b bitboard) bitboard { return b.shl(1).and(l.valid) }
+func (l *layout) east(b bitboard) bitboard  { return b.shl(l.stride).and(l.valid) }
+func (l *layout) west(b bitboard) bitboard  { return b.shr(l.stride).and(l.valid) }
+
+// expand 는 제자리와 상하좌우 한칸씩 넓힌다.
+func (l *layout) expand(b bitboard) bitboard {
+	s := l.stride
+	return b.or(b.shl(1)).or(b.shr(1)).or(b.shl(s)).or(b.shr(s)).and(l.valid)
+}
+
+// cross 는 장애물을 무시한 십자 범위 (Bomb.inRange 와 같다)
+func (l *layout) cross(p Pos, r int) bitboard {
+	var start bitboard
+	start.set(l.index(p))
+	result := start
+	n, s, e, w := start, start, start, start
+	for i := 1; i < r; i++ {
+		n, s, e, w = l.north(n), l.south(s), l.east(e), l.west(w)
+		result = result.or(n).or(s).or(e).or(w)
+	}
+	return result
+}
+
+// bitBoard 는 board [][]int 를 종류별 비트보드로 나눈 것
+type bitBoard struct {
+	walls bitboard
+	boxes [3]bitboard // cellBoxEmpty, cellBoxRange, cellBoxPlus 순
+	bombs bitboard
+	items bitboard
+}
+
+func newBitBoard(board [][]int, bombs []Bomb, items []Item) bitBoard {
+	var bb bitBoard
+	for y := 0; y < geo.h; y++ {
+		for x := 0; x < geo.w; x++ {
+			i := geo.index(Pos{x, y})
+			switch board[y][x] {
+			case cellWall:
+				bb.walls.set(i)
+			case cellBoxEmpty, cellBoxRange, cellBoxPlus:
+				bb.boxes[board[y][x]-cellBoxEmpty].set(i)
+			}
+		}
+	}
+	for _, b := range bombs {
+		// 앞으로 놓을 폭탄은 danger 가 놓인 다음 턴에 넣는다.
+		if b.Placed == 0 {
+			bb.bombs.set(geo.index(b.Pos))
+		}
+	}
+	for _, item := range items {
+		bb.items.set(geo.index(item.Pos))
+	}
+	return bb
+}
+
+func (bb *bitBoard) allBoxes() bitboard {
+	return bb.boxes[0].or(bb.boxes[1]).or(bb.boxes[2])
+}
+
+// blocked 는 폭발이 멈추는 곳 (그 칸까지는 터진다)
+func (bb *bitBoard) stoppers() bitboard {
+	return bb.allBoxes().or(bb.items).or(bb.bombs)
+}
+
+// blast 는 p 에서 range r 로 터질 때 불길이 닿는 칸들.
+// 벽 앞에서 멈추고, 상자/아이템/폭탄은 그 칸까지 터지고 멈춘다.
+func (bb *bitBoard) blast(p Pos, r int) bitboard {
+	var start bitboard
+	start.set(geo.index(p))
+	result := start
+	open := geo.valid.andNot(bb.walls)
+	stop := bb.stoppers()
+	n, s, e, w := start, start, start, start
+	for i := 1; i < r; i++ {
+		n = geo.north(n).and(open)
+		s = geo.south(s).and(open)
+		e = geo.east(e).and(open)
+		w = geo.west(w).and(open)
+		result = result.or(n).or(s).or(e).or(w)
+		n, s, e, w = n.andNot(stop), s.andNot(stop), e.andNot(stop), w.andNot(stop)
+	}
+	return result
+}
+
+// explode 는 d 턴에 터지는 폭탄들을 연쇄폭발까지 따라가서 불길을 구한다.
+// 같이 터진 폭탄은 CountDown 을 d 로 맞추고, 보드에서 지운다.
+func (bb *bitBoard) explode(bombs []Bomb, d int) bitboard {
+	var fire bitboard
+	for {
+		found := false
+		for i := range bombs {
+			b := &bombs[i]
+			idx := geo.index(b.Pos)
+			if b.Placed >= d || !bb.bombs.has(idx) {
+				continue
+			}
+			if b.CountDown == d || fire.has(idx) {
+				b.CountDown = d
+				fire = fire.or(bb.blast(b.Pos, b.Range))
+				bb.bombs.unset(idx)
+				found = true
+			}
+		}
+		if !found {
+			return fire
+		}
+	}
+}
+
+// burn 은 불길이 지나간 자리를 반영한다.
+// 아이템 상자는 터지면 아이템이 남고, 아이템과 빈 상자는 없어진다.
+func (bb *bitBoard) burn(fire bitboard) {
+	bb.items = bb.items.andNot(fire).or(bb.boxes[1].and(fire)).or(bb.boxes[2].and(fire))
+	for i := range bb.boxes {
+		bb.boxes[i] = bb.boxes[i].andNot(fire)
+	}
+}
+
+// danger 는 bombs 를 1..last 턴까지 터뜨리면서 턴별 불길을 돌려준다.
+// bombs 의 CountDown 은 연쇄폭발에 맞춰 바뀐다. 앞으로 놓을 폭탄은 Placed 턴이 지나야 보드에 있다.
+func (bb bitBoard) danger(bombs []Bomb, last int) (fire [bbHorizon]bitboard) {
+	for d := 1; d <= last && d < bbHorizon; d++ {
+		for _, b := range bombs {
+			if b.Placed > 0 && b.Placed == d-1 {
+				bb.bombs.set(geo.index(b.Pos))
+			}
+		}
+		fire[d] = bb.explode(bombs, d)
+		bb.burn(fire[d])
+	}
+	return
+}
@@ -0,0 +1,90 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+// randomBoard 는 w x h 보드를 무작위로 만들어 전역 상태로 놓는다.
+// 홀수 좌표에는 벽이 있고, 나머지 칸은 빈 칸이거나 상자다. 빈 칸 몇 개에는 아이템이 있다.
+func randomBoard(rng *rand.Rand, w, h int) {
+	width, height = w, h
+	setLayout(w, h)
+	board = make([][]int, h)
+	items, bombs, players = nil, nil, nil
+	cells := []int{cellFloor, cellFloor, cellFloor, cellBoxEmpty, cellBoxRange, cellBoxPlus}
+	for y := range board {
+		board[y] = make([]int, w)
+		for x := range board[y] {
+			switch {
+			case x%2 == 1 && y%2 == 1:
+				board[y][x] = cellWall
+			default:
+				board[y][x] = cells[rng.Intn(len(cells))]
+				if board[y][x] == cellFloor && rng.Intn(5) == 0 {
+					items = append(items, Item{Pos{x, y}, 1 + rng.Intn(2)})
+				}
+			}
+		}
+	}
+}
+
+// TestBlastMatchesExplode 는 비트보드 blast 가 태우는 상자와 아이템이
+// 예전 explode 가 부순다고 하는 것과 같은지 본다.
+// 폭탄은 아이템이 없는 빈 칸에만 놓이니까 그런 칸에서만 본다.
+func TestBlastMatchesExplode(t *testing.T) {
+	for seed := int64(1); seed <= 20; seed++ {
+		randomBoard(rand.New(rand.NewSource(seed)), 13, 11)
+		bb := newBitBoard(board, bombs, items)
+		burnable := bb.allBoxes().or(bb.items)
+		for y := 0; y < height; y++ {
+			for x := 0; x < width; x++ {
+				p := Pos{x, y}
+				if board[y][x] != cellFloor || isItem(p) {
+					continue
+				}
+				for r := 2; r <= 6; r++ {
+					var want []int
+					for _, d := range explode(p, r, false) {
+						switch d := d.(type) {
+						case Box:
+							want = append(want, geo.index(d.Pos))
+						case Item:
+							want = append(want, geo.index(d.Pos))
+						}
+					}
+					sort.Ints(want)
+					var got []int
+					fire := bb.blast(p, r).and(burnable)
+					for i := fire.lowest(); i >= 0; i = fire.lowest() {
+						got = append(got, i)
+						fire.unset(i)
+					}
+					if fmt.Sprint(got) != fmt.Sprint(want) {
+						t.Fatalf("seed %d: blast(%v, %d) burns %v, explode %v", seed, p, r, got, want)
+					}
+				}
+			}
+		}
+	}
+}
+
+func TestLayoutIndex(t *testing.T) {
+	tests := []struct{ w, h int }{{13, 11}, {7, 5}, {1, 1}}
+	for _, tt := range tests {
+		setLayout(tt.w, tt.h)
+		if n := geo.valid.count(); n != tt.w*tt.h {
+			t.Errorf("%dx%d: %d valid cells", tt.w, tt.h, n)
+		}
+		for x := 0; x < tt.w; x++ {
+			for y := 0; y < tt.h; y++ {
+				p := Pos{x, y}
+				if q := geo.pos(geo.index(p)); q != p {
+					t.Errorf("%dx%d: pos(index(%v)) = %v", tt.w, tt.h, p, q)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,142 @@
+//go:build ignore
+
+// bundle 은 arena 에 붙여넣을 수 있게 봇을 파일 하나로 합친다.
+//
+//	go run bundle.go > arena.go
+//
+// arena 태그로 빌드되는 파일만 쓰므로 tournament, tune 같은 개발용 명령은 빠진다.
+// arena 는 글자 수 제한이 있어서 주석은 지우고,
+// go:embed 로 넣던 파일(weights.json)은 문자열로 바꿔 넣는다.
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/build"
+	"go/format"
+	"go/parser"
+	"go/printer"
+	"go/token"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func main() {
+	src, err := bundle(".")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, "bundle:", err)
+		os.Exit(1)
+	}
+	os.Stdout.Write(src)
+}
+
+func bundle(dir string) ([]byte, error) {
+	ctx := build.Default
+	ctx.BuildTags = append(ctx.BuildTags, "arena")
+	pkg, err := ctx.ImportDir(dir, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	fset := token.NewFileSet()
+	imports := map[string]bool{}
+	var decls []ast.Decl
+	for _, name := range pkg.GoFiles {
+		f, err := parser.ParseFile(fset, dir+"/"+name, nil, parser.ParseComments)
+		if err != nil {
+			return nil, err
+		}
+		for _, d := range f.Decls {
+			g, ok := d.(*ast.GenDecl)
+			if ok && g.Tok == token.IMPORT {
+				for _, s := range g.Specs {
+					s := s.(*ast.ImportSpec)
+					if s.Name != nil && s.Name.Name == "_" && s.Path.Value == `"embed"` {
+						continue
+					}
+					imports[importLine(s)] = true
+				}
+				continue
+			}
+			if ok {
+				if err := inlineEmbed(dir, g); err != nil {
+					return nil, fmt.Errorf("%s: %w", name, err)
+				}
+			}
+			stripDocs(d)
+			decls = append(decls, d)
+		}
+	}
+
+	var lines []string
+	for l := range imports {
+		lines = append(lines, l)
+	}
+	sort.Strings(lines)
+
+	var buf bytes.Buffer
+	fmt.Fprintf(&buf, "// Code generated by go run bundle.go. DO NOT EDIT.\n\npackage main\n\nimport (\n%s\n)\n", strings.Join(lines, "\n"))
+	// 선언만 따로 찍으면 ast.File.Comments 에 있는 주석은 빠진다. 남은 doc 주석은 stripDocs 가 지웠다.
+	cfg := printer.Config{Mode: printer.UseSpaces | printer.TabIndent, Tabwidth: 8}
+	for _, d := range decls {
+		buf.WriteString("\n")
+		if err := cfg.Fprint(&buf, fset, d); err != nil {
+			return nil, err
+		}
+		buf.WriteString("\n")
+	}
+	return format.Source(buf.Bytes())
+}
+
+func importLine(s *ast.ImportSpec) string {
+	if s.Name != nil {
+		return s.Name.Name + " " + s.Path.Value
+	}
+	return s.Path.Value
+}
+
+// inlineEmbed 는 //go:embed 가 붙은 []byte 변수에 그 파일 내용을 값으로 넣는다.
+func inlineEmbed(dir string, g *ast.GenDecl) error {
+	if g.Doc == nil {
+		return nil
+	}
+	for _, c := range g.Doc.List {
+		pattern, ok := strings.CutPrefix(c.Text, "//go:embed ")
+		if !ok {
+			continue
+		}
+		data, err := os.ReadFile(dir + "/" + strings.TrimSpace(pattern))
+		if err != nil {
+			return err
+		}
+		spec := g.Specs[0].(*ast.ValueSpec)
+		spec.Type = nil
+		spec.Values = []ast.Expr{&ast.CallExpr{
+			Fun:  &ast.ArrayType{Elt: ast.NewIdent("byte")},
+			Args: []ast.Expr{&ast.BasicLit{Kind: token.STRING, Value: strconv.Quote(string(data))}},
+		}}
+	}
+	return nil
+}
+
+// stripDocs 는 선언에 붙은 doc 주석들을 지운다.
+func stripDocs(d ast.Decl) {
+	ast.Inspect(d, func(n ast.Node) bool {
+		switch n := n.(type) {
+		case *ast.FuncDecl:
+			n.Doc = nil
+		case *ast.GenDecl:
+			n.Doc = nil
+		case *ast.TypeSpec:
+			n.Doc, n.Comment = nil, nil
+		case *ast.ValueSpec:
+			n.Doc, n.Comment = nil, nil
+		case *ast.Field:
+			n.Doc, n.Comment = nil, nil
+		}
+		return true
+	})
+}
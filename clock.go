@@ -0,0 +1,51 @@
+package main
+
+import "time"
+
+const (
+	firstTurnTime = 1000 * time.Millisecond
+	turnTime      = 100 * time.Millisecond
+	// timeMargin 는 출력하고 심판이 받을 때까지의 여유
+	timeMargin = 15 * time.Millisecond
+)
+
+// turnClock 은 이번 턴에 남은 시간을 잰다.
+// 0 값은 시간 제한이 없는 시계다. (벤치마크나 리플레이용)
+type turnClock struct {
+	start time.Time
+	limit time.Duration
+}
+
+var clock turnClock
+
+// turn 은 지금까지 읽은 턴 수 (첫 턴이 1)
+var turn int
+
+// startTurn 은 이번 턴의 시계를 시작한다.
+// -nodes 로 노드 수를 정했으면 시간 제한을 두지 않는다. 시간에 따라 bfs 나 think 가
+// 중간에 멈추면 같은 입력에 다른 수를 둘 수 있기 때문이다.
+func startTurn() {
+	turn++
+	if duelNodes > 0 {
+		clock = turnClock{start: time.Now()}
+		return
+	}
+	limit := turnTime
+	if turn == 1 {
+		limit = firstTurnTime
+	}
+	clock = turnClock{time.Now(), limit - timeMargin}
+}
+
+func (c turnClock) expired() bool {
+	return c.limit > 0 && time.Since(c.start) >= c.limit
+}
+
+func (c turnClock) elapsed() time.Duration {
+	return time.Since(c.start)
+}
+
+// spent 는 이번 턴 제한 시간 중 frac 만큼을 이미 썼는지. 제한이 없으면 항상 false
+func (c turnClock) spent(frac float64) bool {
+	return c.limit > 0 && time.Since(c.start) >= time.Duration(float64(c.limit)*frac)
+}
@@ -0,0 +1,28 @@
+package main
+
+import "testing"
+
+// TestStartTurnNodes 는 -nodes 를 쓰면 턴 시계에 시간 제한이 없는지 본다.
+func TestStartTurnNodes(t *testing.T) {
+	defer func(n, t0 int, c turnClock) { duelNodes, turn, clock = n, t0, c }(duelNodes, turn, clock)
+	tests := []struct {
+		nodes int
+		turn  int // startTurn 전의 turn
+		want  bool
+	}{
+		{0, 0, true},
+		{0, 5, true},
+		{20000, 0, false},
+		{20000, 5, false},
+	}
+	for _, tt := range tests {
+		duelNodes, turn = tt.nodes, tt.turn
+		startTurn()
+		if got := clock.limit > 0; got != tt.want {
+			t.Errorf("nodes=%d turn=%d: limit %v", tt.nodes, turn, clock.limit)
+		}
+		if clock.expired() && !tt.want {
+			t.Errorf("nodes=%d turn=%d: clock expired", tt.nodes, turn)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+//go:build !arena
+
+package main
+
+// commands 는 봇 대신 실행하는 개발용 명령들. arena 태그로 빌드하면 모두 빠진다. (bundle.go 참고)
+var commands = map[string]func(args []string){
+	"state":      stateCommand,
+	"extract":    extractCommand,
+	"explain":    explainCommand,
+	"viz":        vizCommand,
+	"genmap":     genmapCommand,
+	"tournament": tournamentCommand,
+	"match":      matchCommand,
+	"tune":       tuneCommand,
+}
@@ -0,0 +1,6 @@
+//go:build arena
+
+package main
+
+// arena 에 올리는 봇에는 개발용 명령이 없다.
+var commands = map[string]func(args []string){}
@@ -0,0 +1,182 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// 후보를 버린 이유
+const (
+	reasonUnsafe   = "unsafe"
+	reasonNoEscape = "no escape"
+	reasonTrap     = "trap risk"
+	reasonTimeout  = "out of time"
+)
+
+// candidate 는 고려한 행동 하나.
+// think 의 item/bomb 후보는 Score 가 weights 로 매긴 점수이고 Features 에 그 특징들이 있다.
+// 나머지는 Features 가 없고 Score 는 종류마다 다르다. escape, hunt 와 참고용 봇의 item 은
+// 도착까지 걸리는 턴 수, 참고용 봇의 bomb 은 터지는 상자 수, duel 은 탐색 점수다.
+type candidate struct {
+	Kind     string    `json:"kind"`
+	Pos      Pos3      `json:"pos"`
+	Score    float64   `json:"score"`
+	Features *features `json:"features,omitempty"`
+	Note     string    `json:"note,omitempty"`
+}
+
+// rejection 은 버린 후보와 그 이유
+type rejection struct {
+	Kind   string `json:"kind"`
+	Pos    Pos3   `json:"pos"`
+	Reason string `json:"reason"`
+}
+
+// decision 은 한 턴에 무엇을 보고 왜 이 행동을 골랐는지 남긴 기록
+type decision struct {
+	Turn       int         `json:"turn"`
+	Candidates []candidate `json:"candidates"`
+	Rejections []rejection `json:"rejections"`
+	Choice     string      `json:"choice"`
+	Reason     string      `json:"reason"`
+	Target     Pos3        `json:"target"`
+	Path       []Pos3      `json:"path,omitempty"`
+}
+
+// trace 는 이번 턴의 기록. think 가 시작할 때 비운다.
+var trace decision
+
+func (d *decision) consider(kind string, pos Pos3, score float64, note string) {
+	d.Candidates = append(d.Candidates, candidate{kind, pos, score, nil, note})
+}
+
+// considerFeatures 는 특징들로 점수를 매긴 후보를 남기고 그 점수를 돌려준다.
+func (d *decision) considerFeatures(kind string, pos Pos3, f features, note string) float64 {
+	score := weights.score(&f)
+	d.Candidates = append(d.Candidates, candidate{kind, pos, score, &f, note})
+	return score
+}
+
+func (d *decision) reject(kind string, pos Pos3, reason string) {
+	d.Rejections = append(d.Rejections, rejection{kind, pos, reason})
+}
+
+func (d *decision) choose(a action, reason string) {
+	d.Choice = a.String()
+	d.Reason = reason
+}
+
+func (a action) String() string {
+	cmd := "MOVE"
+	if a.bomb {
+		cmd = "BOMB"
+	}
+	return fmt.Sprintf("%s %d %d", cmd, a.pos.X, a.pos.Y)
+}
+
+// lines 는 사람이 읽을 수 있게 한 줄씩 쓴다.
+func (d *decision) lines() []string {
+	lines := []string{
+		fmt.Sprintf("turn %d: %s (%s)", d.Turn, d.Choice, d.Reason),
+	}
+	for _, c := range d.Candidates {
+		line := fmt.Sprintf("  + %-6s (%d,%d) t=%d score=%g", c.Kind, c.Pos.X, c.Pos.Y, c.Pos.Z, c.Score)
+		if c.Features != nil {
+			line += " " + c.Features.String()
+		}
+		if c.Note != "" {
+			line += " " + c.Note
+		}
+		lines = append(lines, line)
+	}
+	for _, r := range d.Rejections {
+		lines = append(lines, fmt.Sprintf("  - %-6s (%d,%d) t=%d %s", r.Kind, r.Pos.X, r.Pos.Y, r.Pos.Z, r.Reason))
+	}
+	return lines
+}
+
+// sideBySide 는 두 묶음의 줄을 나란히 쓴다.
+func sideBySide(w io.Writer, left, right []string, gap int) {
+	width := 0
+	for _, l := range left {
+		width = max(width, visibleLen(l))
+	}
+	for i := 0; i < max(len(left), len(right)); i++ {
+		var l, r string
+		if i < len(left) {
+			l = left[i]
+		}
+		if i < len(right) {
+			r = right[i]
+		}
+		fmt.Fprintf(w, "%s%s%s\n", l, strings.Repeat(" ", width-visibleLen(l)+gap), r)
+	}
+}
+
+// visibleLen 은 ANSI 색 코드를 뺀 글자 수
+func visibleLen(s string) int {
+	n := 0
+	esc := false
+	for _, c := range s {
+		switch {
+		case esc:
+			esc = c != 'm'
+		case c == '\x1b':
+			esc = true
+		default:
+			n++
+		}
+	}
+	return n
+}
+
+// explainCommand 는 트랜스크립트를 다시 두면서 턴마다 보드 옆에 결정 기록을 보여준다.
+//
+//	hypersonic explain [transcript]
+func explainCommand(args []string) {
+	fs := flag.NewFlagSet("explain", flag.ExitOnError)
+	var rd renderer
+	fs.BoolVar(&rd.color, "color", false, "draw with ANSI colors")
+	fs.BoolVar(&rd.blast, "blast", true, "overlay blast timing")
+	path := fs.Bool("path", true, "overlay the chosen path and escape targets")
+	fs.Parse(args)
+
+	var r io.Reader = os.Stdin
+	if fs.NArg() > 0 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		r = f
+	}
+	states, err := readStates(r)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+
+	lg.out = io.Discard
+	for i, s := range states {
+		d := explain(s, i+1)
+		r := rd
+		if *path {
+			r = r.withTrace(&d)
+		}
+		sideBySide(os.Stdout, r.render(s), d.lines(), 3)
+		fmt.Println()
+	}
+}
+
+// explain 은 상태 하나에 대해 think 를 돌리고 그 기록을 돌려준다.
+// 시간 제한 없이 끝까지 계산한다.
+func explain(s *State, n int) decision {
+	turn = n
+	clock = turnClock{}
+	s.apply()
+	think()
+	return trace
+}
@@ -0,0 +1,37 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+)
+
+// TestConsiderFeatures 는 think 의 후보가 weights 로 매긴 점수와 그 특징들을 남기는지 본다.
+func TestConsiderFeatures(t *testing.T) {
+	var f features
+	f[featBox], f[featTurns] = 2, 3
+	var d decision
+	score := d.considerFeatures("bomb", Pos3{1, 2, 3}, f, "")
+	if want := weights.score(&f); score != want || d.Candidates[0].Score != want {
+		t.Errorf("score %v, recorded %v, want %v", score, d.Candidates[0].Score, want)
+	}
+	if got := d.lines()[1]; got != fmt.Sprintf("  + bomb   (1,2) t=3 score=%g box=2 turns=3", score) {
+		t.Errorf("line %q", got)
+	}
+	data, err := json.Marshal(d.Candidates[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var c struct{ Features map[string]float64 }
+	if err := json.Unmarshal(data, &c); err != nil {
+		t.Fatal(err)
+	}
+	if c.Features["box"] != 2 || c.Features["turns"] != 3 || len(c.Features) != int(numFeatures) {
+		t.Errorf("features %s", data)
+	}
+
+	d.consider("escape", Pos3{0, 0, 4}, 4, "")
+	if data, _ := json.Marshal(d.Candidates[1]); string(data) != `{"kind":"escape","pos":{"X":0,"Y":0,"Z":4},"score":4}` {
+		t.Errorf("escape %s", data)
+	}
+}
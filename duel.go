@@ -0,0 +1,295 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"sort"
+)
+
+// 1:1 이 되면 상대가 가장 나쁜 수를 둔다고 보고 (paranoid) alpha-beta 로 몇 턴 앞을 본다.
+// 동시에 움직이는 턴을 내가 먼저 고르고 상대가 그걸 보고 고르는 것으로 바꿔서 푼다.
+// 상대의 첫 수는 opponentModel 의 예측대로 정렬하고, 폭탄을 거의 안 놓는 상대면 폭탄 수를 뺀다.
+
+// 평가 점수. 살고 죽는 것이 상자 수보다 항상 크다.
+const (
+	scoreWin  = 1000000 // 게임이 끝났다 (이긴 턴이 빠를수록 조금 더 크다)
+	scoreDoom = 100000  // 아무도 폭탄을 더 놓지 않아도 피할 곳이 없다
+	scoreBox  = 100     // 부순 상자 하나
+	scoreRoom = 1       // 안전하게 갈 수 있는 칸 하나
+)
+
+const (
+	// duelMaxDepth 는 iterative deepening 을 몇 턴까지 할지. 시간이 남아도 여기서 멈춘다.
+	duelMaxDepth = 6
+	// duelRoom 은 mobility 를 셀 때 몇 턴 동안 갈 수 있는 칸을 보는지
+	duelRoom = 4
+	// duelSlack 보다 더 좋은 수가 있을 때만 think 의 선택을 바꾼다.
+	// 그래서 살고 죽는 것이 걸렸을 때만 바뀐다.
+	duelSlack = scoreDoom / 2
+	// duelTime 은 턴 제한 시간 중 탐색에 쓰는 몫. 상대 봇과 CPU 를 나눠 쓸 때도 넘지 않게 남겨둔다.
+	duelTime = 0.6
+)
+
+// duelSearch 는 탐색 한번의 상태
+type duelSearch struct {
+	me, opp int // world.players 안의 위치
+	nodes   int
+	stopped bool // 시간이 다 되어 멈췄다
+	tt      *transTable
+	// buf 는 깊이(ply)마다 쓰는 수 목록 버퍼
+	buf [][]action
+	// replies 는 상대의 첫 수마다 opponentModel 이 예측한 확률.
+	// noBomb 이면 상대가 첫 수로 폭탄을 놓을 확률이 threatProb 보다 작아서 그 경우는 보지 않는다.
+	replies map[action]float64
+	noBomb  bool
+}
+
+// predictReplies 는 지금 전역 상태에서 상대의 첫 수 분포를 구해둔다.
+func (s *duelSearch) predictReplies(opp Player) {
+	s.replies = map[action]float64{}
+	pBomb := 0.0
+	for _, ap := range opponent(opp.ID).predict(opp) {
+		s.replies[ap.action] = ap.P
+		if ap.bomb {
+			pBomb += ap.P
+		}
+	}
+	s.noBomb = pBomb < threatProb
+}
+
+// firstReplies 는 상대의 첫 수들을 예측한 확률이 큰 것부터 놓는다.
+// 상대가 폭탄을 거의 놓지 않으면 폭탄을 놓는 수는 뺀다.
+func (s *duelSearch) firstReplies(moves []action) []action {
+	if s.noBomb {
+		kept := moves[:0]
+		for _, a := range moves {
+			if !a.bomb {
+				kept = append(kept, a)
+			}
+		}
+		moves = kept
+	}
+	sort.SliceStable(moves, func(i, j int) bool { return s.replies[moves[i]] > s.replies[moves[j]] })
+	return moves
+}
+
+// movesAt 은 ply 깊이에서 쓸 i 번 플레이어의 수 목록
+func (s *duelSearch) movesAt(w *world, i, ply int) []action {
+	for len(s.buf) <= ply {
+		s.buf = append(s.buf, make([]action, 0, 10))
+	}
+	s.buf[ply] = w.moves(i, s.buf[ply][:0])
+	return s.buf[ply]
+}
+
+// eval 은 내 쪽에서 본 w 의 점수
+func (s *duelSearch) eval(w *world) int {
+	switch winner := w.winner(); {
+	case winner == w.players[s.me].ID:
+		return scoreWin - w.t
+	case winner == drawn:
+		// 같이 죽으면 상자 수로 순위가 갈린다.
+		return (w.boxes[s.me] - w.boxes[s.opp]) * scoreBox
+	case winner >= 0:
+		return -scoreWin + w.t
+	}
+	score := (w.boxes[s.me] - w.boxes[s.opp]) * scoreBox
+	ok, room := w.escape(duelRoom)
+	if !ok[s.me] {
+		score -= scoreDoom
+	}
+	if !ok[s.opp] {
+		score += scoreDoom
+	}
+	return score + (room[s.me]-room[s.opp])*scoreRoom
+}
+
+// duelNodes 가 0 이 아니면 시간 대신 노드 수로 탐색을 멈추고, 턴 시계도 쓰지 않는다. (startTurn)
+// 그러면 같은 입력에 항상 같은 수를 둬서 자체 대전을 다시 만들어볼 수 있다. (tune 이 쓴다)
+var duelNodes int
+
+func (s *duelSearch) expired() bool {
+	if s.stopped {
+		return true
+	}
+	if duelNodes > 0 {
+		s.stopped = s.nodes >= duelNodes
+	} else if s.nodes&63 == 0 && clock.spent(duelTime) {
+		s.stopped = true
+	}
+	return s.stopped
+}
+
+// max 는 내가 고를 차례. 가장 좋은 점수와 그 행동
+func (s *duelSearch) max(w *world, ply, depth, alpha, beta int) (int, action) {
+	s.nodes++
+	if w.winner() != -1 {
+		return s.eval(w), action{}
+	}
+	e := s.tt.probe(w.hash)
+	if v, ok := s.tt.cutoff(e, w.t, depth, alpha, beta); ok {
+		return v, e.move
+	}
+	if depth == 0 {
+		v := s.eval(w)
+		s.tt.store(w.hash, w.t, 0, alpha, beta, v, action{})
+		return v, action{}
+	}
+	moves := s.movesAt(w, s.me, ply)
+	if e != nil {
+		moveFirst(moves, e.move)
+	}
+	alpha0 := alpha
+	best, bestMove := math.MinInt32, action{}
+	for _, a := range moves {
+		v := s.min(w, a, ply+1, depth, alpha, beta)
+		if s.expired() {
+			return 0, action{}
+		}
+		if v > best {
+			best, bestMove = v, a
+		}
+		if v > alpha {
+			alpha = v
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	s.tt.store(w.hash, w.t, depth, alpha0, beta, best, bestMove)
+	return best, bestMove
+}
+
+// min 은 내가 a 를 고른 것을 보고 상대가 고를 차례.
+// 표에는 w 의 키에 a 를 더한 키로 남긴다.
+func (s *duelSearch) min(w *world, a action, ply, depth, alpha, beta int) int {
+	s.nodes++
+	key := w.hash ^ actionKey(a)
+	e := s.tt.probe(key)
+	if v, ok := s.tt.cutoff(e, w.t, depth, alpha, beta); ok {
+		return v
+	}
+	moves := s.movesAt(w, s.opp, ply)
+	if ply == 1 {
+		moves = s.firstReplies(moves)
+	}
+	if e != nil {
+		moveFirst(moves, e.move)
+	}
+	var acts [zPlayers]action
+	for i, p := range w.players {
+		acts[i] = action{pos: p.Pos}
+	}
+	acts[s.me] = a
+	beta0 := beta
+	best, bestMove := math.MaxInt32, action{}
+	for _, b := range moves {
+		acts[s.opp] = b
+		m := w.mark()
+		w.step(acts[:len(w.players)])
+		v, _ := s.max(w, ply+1, depth-1, alpha, beta)
+		w.undo(m)
+		if s.expired() {
+			return 0
+		}
+		if v < best {
+			best, bestMove = v, b
+		}
+		if v < beta {
+			beta = v
+		}
+		if alpha >= beta {
+			break
+		}
+	}
+	s.tt.store(key, w.t, depth, alpha, beta0, best, bestMove)
+	return best
+}
+
+// moveFirst 는 m 이 moves 에 있으면 맨 앞으로 옮긴다.
+func moveFirst(moves []action, m action) {
+	for i, a := range moves {
+		if a == m {
+			copy(moves[1:i+1], moves[:i])
+			moves[0] = m
+			return
+		}
+	}
+}
+
+// root 는 depth 턴까지 보고 가장 좋은 수와 그 점수, 그리고 prefer 의 점수를 구한다.
+// prefer 를 먼저 full window 로 봐서 그 점수는 정확하다.
+// 그 다음은 지난 깊이에서 가장 좋았던 수를 본다.
+func (s *duelSearch) root(w *world, depth int, prefer action) (best action, bestScore, preferScore int) {
+	moves := append([]action(nil), s.movesAt(w, s.me, 0)...)
+	if e := s.tt.probe(w.hash); e != nil {
+		moveFirst(moves, e.move)
+	}
+	moveFirst(moves, prefer)
+	preferScore = math.MinInt32
+	bestScore = math.MinInt32
+	for _, a := range moves {
+		v := s.min(w, a, 1, depth, bestScore, math.MaxInt32)
+		if s.stopped {
+			return
+		}
+		if a == prefer {
+			preferScore = v
+		}
+		if v > bestScore {
+			best, bestScore = a, v
+		}
+	}
+	s.tt.store(w.hash, w.t, depth, math.MinInt32, math.MaxInt32, bestScore, best)
+	return
+}
+
+// duel 은 살아있는 상대가 하나뿐이면 think 가 고른 a 를 paranoid 탐색으로 확인한다.
+// 더 깊이 볼수록 결과를 바꾸고, 시간이 다 되면 마지막으로 끝까지 본 깊이의 결과를 쓴다.
+// 바꿨으면 true 와 이유를 돌려준다. a 가 둘 수 없는 수면 판단하지 않는다.
+func duel(a action) (action, string, bool) {
+	if !rules.Kills || len(players) != 2 {
+		return a, "", false
+	}
+	w := newWorld()
+	if duelTT == nil {
+		duelTT = newTransTable(ttBits)
+	}
+	duelTT.newSearch()
+	s := duelSearch{me: w.index(myID), opp: 1 - w.index(myID), tt: duelTT}
+	if s.me < 0 || !hasMove(w.moves(s.me, nil), a) {
+		return a, "", false
+	}
+	s.predictReplies(w.players[s.opp])
+	var best action
+	bestScore, preferScore, depth := 0, 0, 0
+	for d := 1; d <= duelMaxDepth; d++ {
+		m, v, pv := s.root(w, d, a)
+		if s.stopped {
+			break
+		}
+		best, bestScore, preferScore, depth = m, v, pv, d
+		// 이기거나 지는 것이 이미 정해졌으면 더 볼 필요가 없다.
+		if v >= scoreWin-duelMaxDepth-w.t || v <= -scoreWin+duelMaxDepth+w.t {
+			break
+		}
+	}
+	if lg.enabled(tagStrategy, levelDebug) {
+		lg.debug(tagStrategy, "duel search", "depth", depth, "nodes", s.nodes, "tt", fmt.Sprintf("%d/%d", s.tt.hits, s.tt.probes), "best", best, "score", bestScore, "prefer", preferScore)
+	}
+	if depth == 0 || bestScore-preferScore <= duelSlack {
+		return a, "", false
+	}
+	trace.reject("duel", a.pos.at(1), reasonTrap)
+	trace.consider("duel", best.pos.at(1), float64(bestScore), "paranoid")
+	return best, "duel: worst case", true
+}
+
+func hasMove(moves []action, a action) bool {
+	for _, m := range moves {
+		if m == a {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package main
+
+import "testing"
+
+// TestDuelUnknownPrefer 는 think 가 고른 수가 둘 수 없는 수면 duel 이 판단하지 않는지 본다.
+func TestDuelUnknownPrefer(t *testing.T) {
+	openState(5, 3, Player{Pos{0, 0}, 0, 1, 3}, Player{Pos{4, 2}, 1, 1, 3}).apply()
+	clock = turnClock{}
+	tests := []struct {
+		name string
+		a    action
+		ok   bool // duel 이 판단하는지 (바꾸는지와는 상관없다)
+	}{
+		{"stay", action{pos: Pos{0, 0}}, true},
+		{"step", action{pos: Pos{1, 0}}, true},
+		{"too far", action{pos: Pos{2, 0}}, false},
+		{"diagonal", action{pos: Pos{1, 1}}, false},
+	}
+	for _, tt := range tests {
+		w := newWorld()
+		if got := hasMove(w.moves(w.index(myID), nil), tt.a); got != tt.ok {
+			t.Errorf("%s: legal %v, want %v", tt.name, got, tt.ok)
+		}
+		if !tt.ok {
+			if b, why, changed := duel(tt.a); changed || b != tt.a || why != "" {
+				t.Errorf("%s: duel changed %v to %v (%s)", tt.name, tt.a, b, why)
+			}
+		}
+	}
+}
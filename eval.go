@@ -0,0 +1,241 @@
+package main
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+// think 는 갈 곳 후보(아이템, 폭탄 놓을 자리)마다 특징들을 구하고
+// 가중치를 곱해 더한 점수가 가장 큰 후보를 고른다.
+// 가중치는 JSON 파일로 바꿀 수 있어서 코드를 고치지 않고 조정할 수 있다.
+
+// feature 는 후보 하나의 특징
+type feature int
+
+const (
+	featBox       feature = iota // 폭탄으로 부술 상자 수
+	featItemBox                  // 그 중 아이템이 든 상자 수
+	featItem                     // 주울 아이템 수
+	featTurns                    // 후보까지 가는 턴 수
+	featMobility                 // 후보에서 mobilitySteps 턴 안에 갈 수 있는 칸 수
+	featDanger                   // 후보 칸에 닿는 폭탄 수
+	featEnemyDist                // 가장 가까운 상대까지의 거리
+	featTerritory                // 상대보다 후보에서 더 가까운 칸 수
+	numFeatures
+)
+
+var featureNames = [numFeatures]string{
+	"box", "itemBox", "item", "turns", "mobility", "danger", "enemyDistance", "territory",
+}
+
+func (f feature) String() string {
+	return featureNames[f]
+}
+
+// mobilitySteps 는 featMobility 를 셀 때 몇 걸음까지 보는지
+const mobilitySteps = 3
+
+// Weights 는 특징별 가중치와 판단에 쓰는 값들.
+// JSON 에서는 특징 이름이 그대로 키가 된다.
+//
+//	{"box": 1, "item": 10, "itemHorizon": 4}
+type Weights struct {
+	W [numFeatures]float64
+	// ItemHorizon 은 몇 턴 안에 갈 수 있는 아이템까지 후보로 보는지
+	ItemHorizon int
+}
+
+// defaultWeightsJSON 은 빌드할 때 넣어두는 기본 가중치
+//
+//go:embed weights.json
+var defaultWeightsJSON []byte
+
+var defaultWeights = mustWeights(defaultWeightsJSON)
+
+var weights = defaultWeights
+
+func mustWeights(data []byte) Weights {
+	var w Weights
+	if err := json.Unmarshal(data, &w); err != nil {
+		panic("weights.json: " + err.Error())
+	}
+	return w
+}
+
+func featureByName(name string) (feature, bool) {
+	for f, n := range featureNames {
+		if n == name {
+			return feature(f), true
+		}
+	}
+	return 0, false
+}
+
+func (w Weights) MarshalJSON() ([]byte, error) {
+	m := map[string]interface{}{"itemHorizon": w.ItemHorizon}
+	for f, v := range w.W {
+		m[featureNames[f]] = v
+	}
+	return json.Marshal(m)
+}
+
+// UnmarshalJSON 은 있는 키만 바꾼다. 모르는 키는 오타일 수 있으니 에러로 한다.
+func (w *Weights) UnmarshalJSON(data []byte) error {
+	var m map[string]json.RawMessage
+	if err := json.Unmarshal(data, &m); err != nil {
+		return err
+	}
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		if k == "itemHorizon" {
+			if err := json.Unmarshal(m[k], &w.ItemHorizon); err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+			continue
+		}
+		f, ok := featureByName(k)
+		if !ok {
+			return fmt.Errorf("unknown weight %q", k)
+		}
+		if err := json.Unmarshal(m[k], &w.W[f]); err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+	}
+	return nil
+}
+
+// loadWeights 는 JSON 파일에서 가중치를 읽는다. 파일에 없는 값은 base 그대로다.
+func loadWeights(path string, base Weights) (Weights, error) {
+	w := base
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return w, err
+	}
+	if err := json.Unmarshal(data, &w); err != nil {
+		return w, fmt.Errorf("%s: %w", path, err)
+	}
+	if w.ItemHorizon < 0 {
+		return w, fmt.Errorf("%s: itemHorizon %d out of range", path, w.ItemHorizon)
+	}
+	return w, nil
+}
+
+// features 는 한 후보의 특징 값들
+type features [numFeatures]float64
+
+// String 은 0 이 아닌 특징들만 "box=2 turns=3" 처럼 쓴다.
+func (f *features) String() string {
+	var parts []string
+	for i, v := range f {
+		if v != 0 {
+			parts = append(parts, fmt.Sprintf("%s=%g", feature(i), v))
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+// MarshalJSON 은 Weights 처럼 특징 이름을 키로 쓴다.
+func (f features) MarshalJSON() ([]byte, error) {
+	m := map[string]float64{}
+	for i, v := range f {
+		m[featureNames[i]] = v
+	}
+	return json.Marshal(m)
+}
+
+func (w *Weights) score(f *features) float64 {
+	s := 0.0
+	for i, v := range f {
+		s += w.W[i] * v
+	}
+	return s
+}
+
+// evalBoard 는 한 턴 동안 후보들이 같이 쓰는 것들
+type evalBoard struct {
+	free    bitboard // 걸어갈 수 있는 칸 (상자, 벽, 폭탄이 없다)
+	enemies bitboard
+}
+
+func newEvalBoard() evalBoard {
+	bb := newBitBoard(board, bombs, items)
+	e := evalBoard{free: geo.valid.andNot(bb.walls).andNot(bb.allBoxes()).andNot(bb.bombs)}
+	for _, p := range players {
+		if p.ID != myID {
+			e.enemies.set(geo.index(p.Pos))
+		}
+	}
+	return e
+}
+
+// features 는 pos 로 가는 후보의 특징들. 상자와 아이템 수는 부르는 쪽이 센다.
+// 계산이 드는 특징은 가중치가 0 이면 세지 않는다.
+func (e *evalBoard) features(pos Pos3, boxes, itemBoxes, items int) features {
+	var f features
+	f[featBox] = float64(boxes)
+	f[featItemBox] = float64(itemBoxes)
+	f[featItem] = float64(items)
+	f[featTurns] = float64(pos.Z)
+	for _, b := range bombs {
+		if rules.Kills && b.inRange(pos.Pos()) {
+			f[featDanger]++
+		}
+	}
+	f[featEnemyDist] = float64(width + height)
+	for _, p := range players {
+		if p.ID != myID {
+			f[featEnemyDist] = float64(min(int(f[featEnemyDist]), manhattan(pos.Pos(), p.Pos)))
+		}
+	}
+
+	var start bitboard
+	start.set(geo.index(pos.Pos()))
+	if weights.W[featMobility] != 0 {
+		reach := start
+		for i := 0; i < mobilitySteps; i++ {
+			reach = reach.or(geo.expand(reach).and(e.free))
+		}
+		f[featMobility] = float64(reach.count())
+	}
+	if weights.W[featTerritory] != 0 {
+		f[featTerritory] = float64(e.territory(start))
+	}
+	return f
+}
+
+// territory 는 from 과 상대들이 동시에 퍼져나갈 때 from 이 먼저 닿는 칸 수.
+// 같은 턴에 닿는 칸은 아무도 갖지 않는다.
+func (e *evalBoard) territory(from bitboard) int {
+	mine, theirs := from, e.enemies
+	seen := mine.or(theirs)
+	owned := mine.andNot(theirs).count()
+	for {
+		nextMine := geo.expand(mine).and(e.free).andNot(seen)
+		nextTheirs := geo.expand(theirs).and(e.free).andNot(seen)
+		if nextMine.isZero() && nextTheirs.isZero() {
+			return owned
+		}
+		owned += nextMine.andNot(nextTheirs).count()
+		seen = seen.or(nextMine).or(nextTheirs)
+		mine, theirs = mine.or(nextMine), theirs.or(nextTheirs)
+	}
+}
+
+// itemBoxes 는 pos 에 range r 로 폭탄을 놓으면 부서질 아이템 상자 수
+func itemBoxes(pos Pos, r int) int {
+	n := 0
+	for _, d := range explode(pos, r, false) {
+		if b, ok := d.(Box); ok && b.Type != cellBoxEmpty {
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,3 @@
+module github.com/jooyunghan/hypersonic
+
+go 1.21
@@ -0,0 +1,165 @@
+package main
+
+// bombRecord 는 누가 언제 놓은 폭탄인지
+type bombRecord struct {
+	Pos   Pos
+	Owner int
+	Range int
+	Turn  int // 처음 보인 턴 (그 전 턴에 놓았다)
+}
+
+// pickupRecord 는 누가 언제 무슨 아이템을 주웠는지
+type pickupRecord struct {
+	Pos  Pos
+	Turn int
+	Type int
+}
+
+// playerHistory 는 플레이어 하나의 지난 기록
+type playerHistory struct {
+	ID        int
+	Positions []Pos // 살아있던 턴마다 위치
+	Dropped   []bombRecord
+	Pickups   []pickupRecord
+	Boxes     int // 이 플레이어 폭탄에 부서진 상자 수 (같이 부순 것도 센다)
+	Died      int // 입력에서 사라진 턴. 0 이면 살아있다
+	Last      Player
+}
+
+// history 는 턴 사이에 남겨두는 기억. round 는 매 턴 상태를 새로 만들기 때문에
+// 지난 턴과 비교해서 알아낼 수 있는 것들은 여기에 모은다.
+type history struct {
+	game    int
+	turn    int
+	prev    *State
+	players map[int]*playerHistory
+	// bombs 는 지금 보드에 있는 폭탄들의 기록
+	bombs map[Pos]bombRecord
+}
+
+var hist history
+
+func (h *history) reset(game int) {
+	*h = history{game: game, players: map[int]*playerHistory{}, bombs: map[Pos]bombRecord{}}
+}
+
+// update 는 이번 턴 상태 s 를 지난 턴과 비교해서 기록한다.
+// game 이 바뀌면 (입력 중간에 새 게임이 시작되면) 처음부터 다시 쌓는다.
+func (h *history) update(s *State, game, turn int) {
+	if h.players == nil || h.game != game {
+		h.reset(game)
+	}
+	prev := h.prev
+	h.prev, h.turn = s, turn
+
+	alive := map[int]bool{}
+	for _, p := range s.Players() {
+		ph := h.players[p.ID]
+		if ph == nil {
+			ph = &playerHistory{ID: p.ID}
+			h.players[p.ID] = ph
+		}
+		ph.Positions = append(ph.Positions, p.Pos)
+		ph.Last = p
+		alive[p.ID] = true
+	}
+	if prev == nil {
+		for _, b := range s.Bombs() {
+			h.bombs[b.Pos] = bombRecord{b.Pos, b.Owner, b.Range, turn}
+		}
+		return
+	}
+
+	for id, ph := range h.players {
+		if !alive[id] && ph.Died == 0 {
+			ph.Died = turn
+			lg.info(tagStrategy, "player gone", "id", id, "turn", turn)
+		}
+	}
+
+	// 새로 생긴 폭탄
+	now := map[Pos]bombRecord{}
+	for _, b := range s.Bombs() {
+		// 처음 보이는 폭탄은 항상 BombTimer 부터 시작한다.
+		r, ok := h.bombs[b.Pos]
+		if !ok || b.CountDown == rules.BombTimer {
+			r = bombRecord{b.Pos, b.Owner, b.Range, turn}
+			if ph := h.players[b.Owner]; ph != nil {
+				ph.Dropped = append(ph.Dropped, r)
+			}
+		}
+		now[b.Pos] = r
+	}
+	h.bombs = now
+
+	h.creditBoxes(prev, s)
+
+	// 사라진 아이템 중에 누가 서 있는 칸은 주운 것
+	here := map[Pos]bool{}
+	for _, it := range s.Items() {
+		here[it.Pos] = true
+	}
+	for _, it := range prev.Items() {
+		if here[it.Pos] {
+			continue
+		}
+		for _, p := range s.Players() {
+			if p.Pos == it.Pos {
+				ph := h.players[p.ID]
+				ph.Pickups = append(ph.Pickups, pickupRecord{it.Pos, turn, it.Type})
+			}
+		}
+	}
+}
+
+// creditBoxes 는 지난 턴에 있다가 없어진 상자를, 그 턴에 터진 폭탄 중 불길이 닿은 폭탄 주인들에게 센다.
+func (h *history) creditBoxes(prev, s *State) {
+	grid := prev.grid()
+	var gone []Pos
+	for y, row := range prev.Board {
+		for x := 0; x < len(row); x++ {
+			c := row[x]
+			if (c == cellBoxEmpty || c == cellBoxRange || c == cellBoxPlus) && s.Board[y][x] == cellFloor {
+				gone = append(gone, Pos{x, y})
+			}
+		}
+	}
+	if len(gone) == 0 {
+		return
+	}
+
+	bs := prev.Bombs()
+	bb := newBitBoard(grid, bs, prev.Items())
+	blasts := make([]bitboard, len(bs))
+	for i, b := range bs {
+		blasts[i] = bb.blast(b.Pos, b.Range)
+	}
+	bb.explode(bs, 1) // 연쇄로 같이 터진 폭탄도 CountDown 이 1 이 된다.
+	for _, p := range gone {
+		owners := map[int]bool{}
+		for i, b := range bs {
+			if b.CountDown == 1 && blasts[i].has(geo.index(p)) {
+				owners[b.Owner] = true
+			}
+		}
+		for id := range owners {
+			if ph := h.players[id]; ph != nil {
+				ph.Boxes++
+			}
+		}
+	}
+}
+
+// stillFor 는 id 가 몇 턴째 제자리에 있는지. (이번 턴 포함하지 않음)
+// 본 적 없거나 죽은 플레이어는 0 이다.
+func (h *history) stillFor(id int) int {
+	ph := h.players[id]
+	if ph == nil || ph.Died != 0 {
+		return 0
+	}
+	n := 0
+	for i := len(ph.Positions) - 1; i > 0 && ph.Positions[i] == ph.Positions[i-1]; i-- {
+		n++
+	}
+	return n
+}
@@ -0,0 +1,125 @@
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// turnState 는 한 줄짜리 보드 row 에 entities 를 놓은 상태
+func turnState(row string, entities ...Entity) *State {
+	return &State{Width: len(row), Height: 1, Board: []string{row}, Entities: entities}
+}
+
+func playerAt(id, x int) Entity      { return Entity{EntityPlayer, id, x, 0, 1, 3} }
+func bombAt(id, x, cd, r int) Entity { return Entity{EntityBomb, id, x, 0, cd, r} }
+func itemAt(x, t int) Entity         { return Entity{EntityItem, 0, x, 0, t, 0} }
+
+// TestHistory 는 턴들을 차례로 넣고 플레이어마다 남은 기록을 본다.
+func TestHistory(t *testing.T) {
+	defer func(r Rules) { rules = r }(rules)
+	rules = defaultRules
+	timer := rules.BombTimer
+	tests := []struct {
+		name  string
+		turns []*State
+		id    int
+		field string // 볼 기록
+		want  string
+	}{
+		{"drop", []*State{
+			turnState(".....", playerAt(0, 0), playerAt(1, 4)),
+			turnState(".....", playerAt(0, 1), playerAt(1, 4), bombAt(0, 0, timer, 3)),
+			turnState(".....", playerAt(0, 2), playerAt(1, 4), bombAt(0, 0, timer-1, 3)),
+		}, 0, "dropped", "[{{0 0} 0 3 2}]"},
+		{"drop on the same cell again", []*State{
+			turnState(".....", playerAt(0, 0), playerAt(1, 4), bombAt(0, 0, 1, 3)),
+			turnState(".....", playerAt(0, 0), playerAt(1, 4), bombAt(0, 0, timer, 3)),
+		}, 0, "dropped", "[{{0 0} 0 3 2}]"},
+		{"other player's bomb", []*State{
+			turnState(".....", playerAt(0, 0), playerAt(1, 4)),
+			turnState(".....", playerAt(0, 0), playerAt(1, 4), bombAt(1, 4, timer, 2)),
+		}, 0, "dropped", "[]"},
+		{"box", []*State{
+			turnState("..0..", playerAt(0, 4), bombAt(0, 0, 1, 3)),
+			turnState(".....", playerAt(0, 4)),
+		}, 0, "boxes", "1"},
+		{"box shared", []*State{
+			turnState("..1..", playerAt(0, 0), playerAt(1, 4), bombAt(0, 0, 1, 3), bombAt(1, 4, 1, 3)),
+			turnState(".....", playerAt(0, 0), playerAt(1, 4)),
+		}, 1, "boxes", "1"},
+		{"box by chain", []*State{
+			turnState("0....", playerAt(0, 4), playerAt(1, 4), bombAt(0, 3, 1, 2), bombAt(1, 2, 5, 3)),
+			turnState(".....", playerAt(0, 4), playerAt(1, 4)),
+		}, 1, "boxes", "1"},
+		{"box out of reach", []*State{
+			turnState("0....", playerAt(0, 4), bombAt(0, 3, 1, 3)),
+			turnState(".....", playerAt(0, 4)),
+		}, 0, "boxes", "0"},
+		{"pickup", []*State{
+			turnState(".....", playerAt(0, 0), itemAt(1, 2), itemAt(3, 1)),
+			turnState(".....", playerAt(0, 1), itemAt(3, 1)),
+		}, 0, "pickups", "[{{1 0} 2 2}]"},
+		{"item burned", []*State{
+			turnState(".....", playerAt(0, 0), itemAt(3, 1)),
+			turnState(".....", playerAt(0, 0)),
+		}, 0, "pickups", "[]"},
+		{"death", []*State{
+			turnState(".....", playerAt(0, 0), playerAt(1, 4)),
+			turnState(".....", playerAt(0, 0), playerAt(1, 4)),
+			turnState(".....", playerAt(0, 0)),
+			turnState(".....", playerAt(0, 0)),
+		}, 1, "died", "3"},
+		{"alive", []*State{
+			turnState(".....", playerAt(0, 0), playerAt(1, 4)),
+			turnState(".....", playerAt(0, 0)),
+		}, 0, "died", "0"},
+		{"still", []*State{
+			turnState(".....", playerAt(0, 1)),
+			turnState(".....", playerAt(0, 0)),
+			turnState(".....", playerAt(0, 0)),
+			turnState(".....", playerAt(0, 0)),
+		}, 0, "still", "2"},
+		{"still but dead", []*State{
+			turnState(".....", playerAt(0, 0), playerAt(1, 4)),
+			turnState(".....", playerAt(0, 0), playerAt(1, 4)),
+			turnState(".....", playerAt(0, 0)),
+		}, 1, "still", "0"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var h history
+			for i, s := range tt.turns {
+				setLayout(s.Width, s.Height)
+				h.update(s, 1, i+1)
+			}
+			ph := h.players[tt.id]
+			var got string
+			switch tt.field {
+			case "dropped":
+				got = fmt.Sprint(ph.Dropped)
+			case "boxes":
+				got = fmt.Sprint(ph.Boxes)
+			case "pickups":
+				got = fmt.Sprint(ph.Pickups)
+			case "died":
+				got = fmt.Sprint(ph.Died)
+			case "still":
+				got = fmt.Sprint(h.stillFor(tt.id))
+			}
+			if got != tt.want {
+				t.Errorf("%s %s, want %s", tt.field, got, tt.want)
+			}
+		})
+	}
+}
+
+// TestHistoryNewGame 은 입력 중간에 새 게임이 시작되면 기록을 비우는지 본다.
+func TestHistoryNewGame(t *testing.T) {
+	var h history
+	setLayout(5, 1)
+	h.update(turnState(".....", playerAt(0, 0), playerAt(1, 4)), 1, 1)
+	h.update(turnState(".....", playerAt(0, 0)), 2, 1)
+	if len(h.players) != 1 || h.players[0].Died != 0 || len(h.players[0].Positions) != 1 {
+		t.Errorf("history not reset: %d players, %+v", len(h.players), h.players[0])
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// leagues 는 리그별 규칙.
+// Wood 리그는 상자 수로만 순위를 매기고, 아이템이나 폭탄에 죽는 규칙이 단계별로 추가된다.
+var leagues = map[string]Rules{
+	"wood3":  withLeague(defaultRules, "wood3", false, false),
+	"wood2":  withLeague(defaultRules, "wood2", true, false),
+	"wood1":  withLeague(defaultRules, "wood1", true, true),
+	"bronze": defaultRules,
+}
+
+func withLeague(r Rules, name string, items, kills bool) Rules {
+	r.League, r.Items, r.Kills = name, items, kills
+	return r
+}
+
+// leagueAuto 면 첫 턴의 입력을 보고 리그를 정한다.
+var leagueAuto = true
+
+func leagueNames() string {
+	var names []string
+	for name := range leagues {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// setLeague 는 -league 플래그 값을 반영한다. "auto" 는 입력을 보고 정한다.
+func setLeague(name string) error {
+	if name == "auto" {
+		leagueAuto = true
+		return nil
+	}
+	r, ok := leagues[name]
+	if !ok {
+		return fmt.Errorf("unknown league %q (auto, %s)", name, leagueNames())
+	}
+	leagueAuto = false
+	rules = r
+	return nil
+}
+
+// detectLeague 는 첫 턴에서 리그를 짐작한다.
+// 상자는 있는데 아이템 상자도 아이템도 없으면 상자만 부수는 wood3 이고,
+// 아니면 모르니까 지금 규칙 그대로 둔다. (폭탄에 죽는지는 입력만 봐서는 알 수 없다.)
+func detectLeague(s *State) string {
+	boxes := false
+	for _, row := range s.Board {
+		if strings.ContainsAny(row, string([]byte{cellBoxRange, cellBoxPlus})) {
+			return rules.League
+		}
+		boxes = boxes || strings.IndexByte(row, cellBoxEmpty) >= 0
+	}
+	if !boxes || len(s.Items()) > 0 {
+		return rules.League
+	}
+	return "wood3"
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestDetectLeague(t *testing.T) {
+	defer func(r Rules) { rules = r }(rules)
+	rules = defaultRules
+	tests := []struct {
+		name  string
+		board []string
+		items int
+		want  string
+	}{
+		{"boxes only", []string{"..0", ".X.", "0.."}, 0, "wood3"},
+		{"item boxes", []string{"..1", ".X.", "0.."}, 0, rules.League},
+		{"items", []string{"..0", ".X.", "0.."}, 1, rules.League},
+		{"no boxes", []string{"...", ".X.", "..."}, 0, rules.League},
+	}
+	for _, tt := range tests {
+		s := &State{Width: 3, Height: 3, Board: tt.board}
+		for i := 0; i < tt.items; i++ {
+			s.Entities = append(s.Entities, Entity{EntityItem, 0, 0, 0, 1, 0})
+		}
+		if got := detectLeague(s); got != tt.want {
+			t.Errorf("%s: %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
+
+// TestDetectLeagueKeepsSize 는 리그를 바꿔도 입력에서 읽은 보드 크기가 남는지 본다.
+func TestDetectLeagueKeepsSize(t *testing.T) {
+	defer func(r Rules, auto bool) { rules, leagueAuto = r, auto }(rules, leagueAuto)
+	rules, leagueAuto = defaultRules, true
+	g := game{newParser(strings.NewReader("5 3 0\n..0..\n.X.X.\n..0..\n1\n0 0 0 0 1 3\n")), io.Discard, mainBot{}}
+	if err := g.init(); err != nil {
+		t.Fatal(err)
+	}
+	if !g.read() {
+		t.Fatal("no turn")
+	}
+	if rules.League != "wood3" || rules.Width != 5 || rules.Height != 3 {
+		t.Errorf("rules %s %dx%d, want wood3 5x3", rules.League, rules.Width, rules.Height)
+	}
+}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"sync"
+)
+
+// level 은 로그 수준
+type level int
+
+const (
+	levelTrace level = iota
+	levelDebug
+	levelInfo
+	levelWarn
+	levelOff
+)
+
+var levelNames = [...]string{"trace", "debug", "info", "warn", "off"}
+
+// tag 는 로그를 남기는 부분
+type tag int
+
+const (
+	tagParser tag = iota
+	tagBFS
+	tagBombs
+	tagStrategy
+	tagReferee
+	numTags
+)
+
+var tagNames = [numTags]string{"parser", "bfs", "bombs", "strategy", "referee"}
+
+// logger 는 tag 마다 수준을 따로 정할 수 있는 로거.
+// 아레나 stderr 는 턴당 32KB 라서 기본은 warn 만 남긴다.
+// 토너먼트에서는 여러 게임이 같이 로그를 남기니까 buf 는 mu 로 지킨다.
+type logger struct {
+	out    io.Writer
+	levels [numTags]level
+
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+var lg = newLogger(os.Stderr, envOr("HS_LOG", "warn"))
+
+func newLogger(out io.Writer, spec string) *logger {
+	l := &logger{out: out}
+	if err := l.configure(spec); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		l.configure("warn")
+	}
+	return l
+}
+
+func parseLevel(s string) (level, error) {
+	for i, name := range levelNames {
+		if s == name {
+			return level(i), nil
+		}
+	}
+	return levelOff, fmt.Errorf("log: unknown level %q", s)
+}
+
+// configure 는 "debug" 나 "warn,bfs=trace,strategy=debug" 같은 설정을 읽는다.
+// tag 가 없는 값은 모든 tag 의 수준이 된다.
+func (l *logger) configure(spec string) error {
+	levels := l.levels
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(part, "=")
+		if !ok {
+			lv, err := parseLevel(name)
+			if err != nil {
+				return err
+			}
+			for t := range levels {
+				levels[t] = lv
+			}
+			continue
+		}
+		lv, err := parseLevel(value)
+		if err != nil {
+			return err
+		}
+		found := false
+		for t, tn := range tagNames {
+			if tn == name {
+				levels[t] = lv
+				found = true
+			}
+		}
+		if !found {
+			return fmt.Errorf("log: unknown tag %q", name)
+		}
+	}
+	l.levels = levels
+	return nil
+}
+
+func (l *logger) enabled(t tag, lv level) bool {
+	return lv >= l.levels[t]
+}
+
+// log 는 한 줄로 "D strategy: msg k=v k=v" 처럼 남긴다.
+func (l *logger) log(t tag, lv level, msg string, kv ...interface{}) {
+	if !l.enabled(t, lv) {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	w := &l.buf
+	w.Reset()
+	fmt.Fprintf(w, "%c %s: %s", strings.ToUpper(levelNames[lv])[0], tagNames[t], msg)
+	for i := 0; i+1 < len(kv); i += 2 {
+		fmt.Fprintf(w, " %v=%v", kv[i], kv[i+1])
+	}
+	if len(kv)%2 == 1 {
+		fmt.Fprintf(w, " %v", kv[len(kv)-1])
+	}
+	w.WriteByte('\n')
+	l.out.Write(w.Bytes())
+}
+
+func (l *logger) trace(t tag, msg string, kv ...interface{}) { l.log(t, levelTrace, msg, kv...) }
+func (l *logger) debug(t tag, msg string, kv ...interface{}) { l.log(t, levelDebug, msg, kv...) }
+func (l *logger) info(t tag, msg string, kv ...interface{})  { l.log(t, levelInfo, msg, kv...) }
+func (l *logger) warn(t tag, msg string, kv ...interface{})  { l.log(t, levelWarn, msg, kv...) }
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"bytes"
+	"strings"
+	"sync"
+	"testing"
+)
+
+func TestLoggerConfigure(t *testing.T) {
+	tests := []struct {
+		spec string
+		tag  tag
+		want level
+		ok   bool
+	}{
+		{"warn", tagBFS, levelWarn, true},
+		{"debug", tagParser, levelDebug, true},
+		{"warn,bfs=trace", tagBFS, levelTrace, true},
+		{"warn,bfs=trace", tagBombs, levelWarn, true},
+		{"loud", tagBFS, levelWarn, false},
+		{"warn,nope=debug", tagBFS, levelWarn, false},
+	}
+	for _, tt := range tests {
+		l := newLogger(nil, "warn")
+		err := l.configure(tt.spec)
+		if (err == nil) != tt.ok {
+			t.Errorf("%q: error %v", tt.spec, err)
+		}
+		if l.levels[tt.tag] != tt.want {
+			t.Errorf("%q: %s level %s, want %s", tt.spec, tagNames[tt.tag], levelNames[l.levels[tt.tag]], levelNames[tt.want])
+		}
+	}
+}
+
+// TestLoggerConcurrent 는 여러 고루틴이 같이 남겨도 줄이 섞이지 않는지 본다.
+func TestLoggerConcurrent(t *testing.T) {
+	var out bytes.Buffer
+	l := newLogger(&out, "debug")
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				l.debug(tagStrategy, "hello", "g", i, "n", n)
+			}
+		}(i)
+	}
+	wg.Wait()
+	lines := strings.Split(strings.TrimSuffix(out.String(), "\n"), "\n")
+	if len(lines) != 800 {
+		t.Fatalf("%d lines, want 800", len(lines))
+	}
+	for _, line := range lines {
+		if !strings.HasPrefix(line, "D strategy: hello g=") {
+			t.Fatalf("broken line %q", line)
+		}
+	}
+}
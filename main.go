@@ -1,6 +1,8 @@
 package main
 
 import (
+	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"os"
@@ -8,11 +10,6 @@ import (
 	"strings"
 )
 
-func debug(f string, args ...interface{}) {
-	fmt.Fprintf(os.Stderr, f, args...)
-	fmt.Fprintln(os.Stderr)
-}
-
 var dist [][]int
 var board [][]int
 var score [][]int
@@ -80,9 +77,12 @@ func (p Pos3) safePathTo(dest Pos3, bombs []Bomb) Pos3 {
 		return dest
 	}
 
-	debug("want to go %v", dest)
+	lg.debug(tagStrategy, "want to go", "dest", dest)
+	return firstStep(p.pathTo(dest, bombs), p)
+}
 
-	// debug("bfs start")
+// pathTo 는 dest 까지 안전하게 가는 경로. 못 가면 nil
+func (p Pos3) pathTo(dest Pos3, bombs []Bomb) []Pos3 {
 	path, _ := bfs(p, bombs, items, func(x, y, d, x0, y0 int, bombs []Bomb, items []Item) bool {
 		pos := Pos3{x, y, d}
 		// debug("bfs: %d,%d,%d,%d,%d", x, y, d, x0, y0)
@@ -91,8 +91,8 @@ func (p Pos3) safePathTo(dest Pos3, bombs []Bomb) Pos3 {
 		}
 		return false
 	})
-
-	return path[0]
+	// bfs 의 경로는 엔진 버퍼라서 복사해둔다.
+	return append([]Pos3(nil), path...)
 }
 
 func (p Pos) down(i int) Pos {
@@ -129,6 +129,10 @@ type Bomb struct {
 	Owner     int
 	CountDown int
 	Range     int
+	// Placed 는 앞으로 놓을 폭탄이 놓이는 턴 (bfs 의 d 로 Placed 부터 그 칸에 못 들어간다).
+	// 그 턴의 폭발보다 늦게 놓이니까 Placed 턴까지의 불길로는 연쇄폭발하지 않는다.
+	// 0 이면 이미 놓여있다.
+	Placed int
 }
 
 const (
@@ -147,10 +151,10 @@ type Item struct {
 func canGo(p Pos3, bombs []Bomb) bool {
 	if inRange2D(p.X, p.Y, width, height) && board[p.Y][p.X] == cellFloor {
 		for _, b := range bombs {
-			if b.Pos == p.Pos() {
+			if b.Pos == p.Pos() && p.Z >= b.Placed {
 				return false
 			}
-			if b.CountDown-1 == p.Z && b.inRange(p.Pos()) {
+			if b.CountDown-1 == p.Z && rules.Kills && b.inRange(p.Pos()) {
 				return false
 			}
 		}
@@ -214,63 +218,6 @@ func (set SetPos3) toSlice() []Pos3 {
 	return slice
 }
 
-// bfs 는 시간 축(d)을 고려하고,
-// d는 항상 증가하기 때문에,
-// 어차피 방문한 곳을 또 방문할 일이 없다.
-// 즉, 현 상태의 bombs를 보고
-// 안전한 경로로 bfs를 진행해보자.
-func bfs(pos Pos3, bombs []Bomb, items []Item, visit func(x, y, d, x0, y0 int, bombs []Bomb, items []Item) bool) ([]Pos3, bool) {
-	back := map[Pos3]Pos3{}
-	getPath := func(next Pos3) []Pos3 {
-		sz := next.Z - pos.Z
-		path := make([]Pos3, sz)
-		sz--
-		path[sz] = next
-		for pos != back[next] {
-			next = back[next]
-			sz--
-			path[sz] = next
-		}
-		// debug("%v", path)
-		return path
-	}
-
-	layer := []Pos3{pos}
-	if visit(pos.X, pos.Y, pos.Z, pos.X, pos.Y, bombs, items) {
-		return nil, true
-	}
-
-	dxs := []int{0, 0, 1, 0, -1}
-	dys := []int{0, 1, 0, -1, 0}
-	d := pos.Z
-	for i := 0; len(layer) > 0 && i <= width; i++ {
-
-		bombs = removeOld(bombs, d)
-		// item도 없어져야 하고..
-		// 상자도 없어져야 하고..
-		// 그러면 아이템도 생겨야 하고..
-
-		var newLayer = SetPos3{}
-		for _, p := range layer {
-			for k := 0; k < 5; k++ {
-				dx := dxs[k]
-				dy := dys[k]
-				next := Pos3{p.X + dx, p.Y + dy, p.Z + 1}
-				if canGo(next, bombs) && !newLayer.has(next) {
-					newLayer.add(next)
-					back[next] = p
-					if visit(next.X, next.Y, next.Z, p.X, p.Y, bombs, items) {
-						return getPath(next), true
-					}
-				}
-			}
-		}
-		layer = newLayer.toSlice()
-	}
-
-	return nil, false
-}
-
 // // World ...
 // type World struct {
 // 	board [][]int
@@ -329,40 +276,6 @@ func bfs(pos Pos3, bombs []Bomb, items []Item, visit func(x, y, d, x0, y0 int, b
 // 	}
 // }
 
-func debugB(b [][]int) {
-	var line []string
-	var lines []string
-	for h := 0; h < height; h++ {
-		for w := 0; w < width; w++ {
-			line = append(line, fmt.Sprint(b[h][w]))
-		}
-		lines = append(lines, strings.Join(line, " "))
-		line = nil
-	}
-	debug(strings.Join(lines, "\n"))
-}
-
-func debugM(m map[int][]Pos) {
-	max := 0
-	for k := range m {
-		if k > max {
-			max = k
-		}
-	}
-	line := []string{}
-	lines := []string{}
-	for i := 0; i <= max; i++ {
-		if s, ok := m[i]; ok {
-			for _, p := range s {
-				line = append(line, fmt.Sprintf("(%d,%d)", p.X, p.Y))
-			}
-			lines = append(lines, strings.Join(line, " "))
-			line = nil
-		}
-	}
-	debug(strings.Join(lines, "\n"))
-}
-
 func isValid(p Pos) bool {
 	return p.X >= 0 && p.X < width && p.Y >= 0 && p.Y < height
 }
@@ -525,88 +438,29 @@ func inRange2D(x, y, w, h int) bool {
 	return inRange(x, 0, w) && inRange(y, 0, h)
 }
 
-func explode2(bombs []Bomb, board [][]int, p Pos, s SetPos) {
-	if s.has(p) {
-		return
-	}
-	s.add(p)
-
-	x, y := p.X, p.Y
-	if board[y][x] < 100 { // not a bomb
-		return
-	}
-	r := bombs[board[y][x]-100].Range
-	dxs := []int{1, 0, -1, 0}
-	dys := []int{0, 1, 0, -1}
-
-	for d := 0; d < 4; d++ {
-		dx := dxs[d]
-		dy := dys[d]
-		x, y = p.X, p.Y
-		for i := 1; i < r; i++ {
-			x += dx
-			y += dy
-			if !inRange2D(x, y, width, height) {
-				break
-			}
-			if board[y][x] == cellFloor {
-				continue
-			} else if board[y][x] == cellWall {
-				break
-			} else {
-				explode2(bombs, board, Pos{x, y}, s)
-				break
-			}
-		}
-	}
-}
-
 // syncBombs 는 폭탄의 연쇄폭발로 같이 터지는 폭탄들의
 // countdown 값을 일치시켜놓는다.
 func syncBombs(bombs []Bomb, board [][]int, items []Item) {
 	if len(bombs) < 2 {
 		return
 	}
-	// boards와 items는 실제로 터뜨릴 거다. 그래서 복사해둔다.
-	board = copy2D(board)
-	// items도 board에 미리 놔둔다. 이건 그냥 보통 박스(0)라고 볼 수 있다.
-	for _, item := range items {
-		board[item.Pos.Y][item.Pos.X] = cellBoxEmpty
-	}
-	// 폭탄도 미리 놔두자. 이건 100 + index 로..
-	for i, b := range bombs {
-		board[b.Pos.Y][b.Pos.X] = 100 + i
-	}
-	// itemBox(1,2) 는 터뜨려도 또 item이 남는다.
-
-	for d := 1; d <= 9; d++ {
-		s := SetPos{}
-		for i := range bombs {
-			p := bombs[i].Pos
-			if bombs[i].CountDown == d && board[p.Y][p.X] >= 100 {
-				explode2(bombs, board, bombs[i].Pos, s)
-			}
-		}
+	// 비트보드는 값 복사라서 board 와 items 는 그대로 남는다.
+	bb := newBitBoard(board, bombs, items)
+	bb.danger(bombs, rules.BombTimer+1)
+}
 
-		// 터진 것들을 반영한다.
-		for _, p := range s.toSlice() {
-			// debug("%d: %v", d, p)
-			x, y := p.X, p.Y
-			switch board[y][x] {
-			case cellBoxPlus, cellBoxRange:
-				board[y][x] = cellBoxEmpty
-			case cellBoxEmpty:
-				board[y][x] = cellFloor
-			default:
-				// sync 맞춘다.
-				bombs[board[y][x]-100].CountDown = d
-				board[y][x] = cellFloor
-			}
-		}
+// action 은 한 턴에 내는 명령
+type action struct {
+	bomb bool
+	pos  Pos
+}
+
+// firstStep 은 경로의 첫 칸. 경로가 없으면 (이미 안전하거나 못 찾았으면) 제자리
+func firstStep(path []Pos3, from Pos3) Pos3 {
+	if len(path) == 0 {
+		return from.Pos().at(from.Z + 1)
 	}
-	// for i, b := range bombs {
-	// 	debug("bombs[%d] = %v", i, b)
-	// }
+	return path[0]
 }
 
 func (r game) move(bomb bool, pos Pos) {
@@ -617,156 +471,202 @@ func (r game) move(bomb bool, pos Pos) {
 	fmt.Fprintf(r, "%s %d %d\n", cmd, pos.X, pos.Y)
 }
 
-func (r game) init() {
+func (r game) init() error {
 	// begin game
-	fmt.Fscan(r, &width, &height, &myID)
+	r.in.onTurn = startTurn
+	if err := r.in.readInit(); err != nil {
+		return err
+	}
+	width, height, myID = r.in.width, r.in.height, r.in.myID
+	rules.Width, rules.Height = width, height
+	setLayout(width, height)
+	return nil
 }
 
 func (r game) round() bool {
+	if !r.read() {
+		return false
+	}
+	a := r.bot.act(current)
+	r.move(a.bomb, a.pos)
+	return true
+}
 
-	// read status
-	board = make([][]int, height)
-	for i := 0; i < height; i++ {
-		board[i] = make([]int, width)
-
-		var row string
-		fmt.Fscan(r, &row)
-		debug(row)
-		if len(row) != width {
-			debug("wrong input. exit.")
+// read 는 한 턴을 읽어서 전역변수에 반영한다.
+// 잘못된 입력은 보고하고 다음 턴까지 건너뛴다.
+func (r game) read() bool {
+	for {
+		s, err := r.in.readTurn()
+		if err == io.EOF {
 			return false
 		}
-
-		for w := 0; w < width; w++ {
-			board[i][w] = int(row[w])
+		if err != nil {
+			lg.warn(tagParser, "bad input", "err", err)
+			if errors.Is(err, io.ErrUnexpectedEOF) || r.in.resync() != nil {
+				return false
+			}
+			continue
 		}
-	}
 
-	var n int
-	fmt.Fscan(r, &n)
-	debug("%d", n)
-
-	players = nil
-	bombs = nil
-	items = nil
-	for i := 0; i < n; i++ {
-		var entityType, owner, x, y, param1, param2 int
-		fmt.Fscan(r, &entityType, &owner, &x, &y, &param1, &param2)
-		debug("%d %d %d %d %d %d", entityType, owner, x, y, param1, param2)
-
-		p := Pos{x, y}
-		switch entityType {
-		case EntityPlayer:
-			player := Player{Pos: p, ID: owner, Bombs: param1, Range: param2}
-			players = append(players, player)
-			if owner == myID {
-				me = player
+		writeSnapshot(lg.out, turn, s)
+		if leagueAuto {
+			if name := detectLeague(s); name != rules.League {
+				lg.info(tagStrategy, "league detected", "league", name)
+				l := leagues[name]
+				rules = withLeague(rules, name, l.Items, l.Kills)
 			}
-		case EntityBomb:
-			bombs = append(bombs, Bomb{Pos: p, Owner: owner, CountDown: param1, Range: param2})
-		case EntityItem:
-			items = append(items, Item{Pos: p, Type: param1})
+			leagueAuto = false
 		}
+		s.apply()
+		updateOpponents(hist.prev, s, hist.game != r.in.games)
+		hist.update(s, r.in.games, turn)
+		return true
 	}
+}
 
-	// bombs sync
-	syncBombs(bombs, board, items)
-
+// think 는 지금 전역 상태에서 할 행동을 정한다. 고른 이유는 trace 에 남는다.
+func think() action {
 	// 우선 주변을 둘러보자.
 	// 갈수 있는곳..
 	// 뭐가 있을까? 적? 아이템? 박스? 폭탄?
 
+	trace = decision{Turn: turn}
 	dropBomb := false
 	posToGo := me.Pos.at(0)
 	origin := me.Pos.at(0)
 	found := false
 
-	type bombScore struct {
-		pos  Pos3
-		n    int
-		toGo Pos3
+	// 시간이 모자랄 때 낼 행동. 지금 있는 폭탄들만 피한다.
+	fallback := action{pos: origin.Pos()}
+	if path, ok := me.canEscapeFrom(origin, bombs); ok {
+		fallback.pos = firstStep(path, origin).Pos()
+	}
+	outOfTime := func(phase string) bool {
+		if !clock.expired() {
+			return false
+		}
+		lg.warn(tagStrategy, "out of time", "phase", phase, "elapsed", clock.elapsed(), "fallback", fallback)
+		trace.reject(phase, origin, reasonTimeout)
+		trace.choose(fallback, "fallback: out of time")
+		return true
+	}
+	why := "stay"
+
+	// target 은 갈 곳 후보. weights 로 매긴 점수가 가장 큰 곳으로 간다.
+	type target struct {
+		kind  string // item, bomb
+		pos   Pos3
+		n     int // bomb 이면 부술 상자 수
+		toGo  Pos3
+		score float64
 	}
+	var targets []target
+	eb := newEvalBoard()
 
-	debug("item?")
+	lg.trace(tagStrategy, "looking for items")
 	bfs(origin, bombs, items, func(x, y, d, x0, y0 int, bombs []Bomb, items []Item) bool {
-		if d > 4 {
+		// 아이템이 없는 리그는 상자만 본다.
+		if d > weights.ItemHorizon || !rules.Items {
 			return true
 		}
 		pos := Pos3{x, y, d}
 		for _, e := range items {
 			if e.Pos == pos.Pos() {
-				debug("found an item %d,%d,%d", x, y, d)
+				// bfs 콜백은 자주 불리니까 인자를 만들기 전에 수준부터 본다.
+				if lg.enabled(tagStrategy, levelDebug) {
+					lg.debug(tagStrategy, "found an item", "pos", pos)
+				}
 				// 하지만 먹고나서 괜찮을까?
 				safe, ok := me.canEscapeFrom(pos, bombs)
 				if !ok {
-					debug("but, can't escape from there")
+					if lg.enabled(tagStrategy, levelDebug) {
+						lg.debug(tagStrategy, "but, can't escape from there", "pos", pos)
+					}
+					trace.reject("item", pos, reasonNoEscape)
 					return false
 				}
-				debug("safepath from %v=  %v", pos, safe)
-				posToGo = pos
-				found = true
-				return true
+				if lg.enabled(tagStrategy, levelTrace) {
+					lg.trace(tagStrategy, "safe path", "from", pos, "path", safe)
+				}
+				score := trace.considerFeatures("item", pos, eb.features(pos, 0, 0, 1), "")
+				targets = append(targets, target{"item", pos, 0, pos, score})
 			}
 		}
 		return false
 	})
 
-	if !found {
-		candidates := []bombScore{}
+	if outOfTime("item") {
+		return fallback
+	}
 
+	// 갈 만한 아이템이 있으면 폭탄 놓을 자리는 찾지 않는다.
+	if len(targets) == 0 {
 		bfs(origin, bombs, items, func(x, y, d, x0, y0 int, bombs []Bomb, items []Item) bool {
 			pos := Pos3{x, y, d}
 			ok, safe, n := me.canDropBomb(pos, bombs)
 			if ok {
-				// debug("bomb at %v with %d boxes", pos, n)
-				candidates = append(candidates, bombScore{pos, n, safe})
+				score := trace.considerFeatures("bomb", pos, eb.features(pos, n, itemBoxes(pos.Pos(), me.Range), 0), "")
+				targets = append(targets, target{"bomb", pos, n, safe, score})
+			} else if n > 0 {
+				trace.reject("bomb", pos, reasonNoEscape)
 			}
 
 			return false
 		})
+	}
 
-		if len(candidates) > 0 {
-			best := candidates[0]
-			for _, c := range candidates {
-				if best.n < c.n {
-					best = c
-				}
+	if len(targets) > 0 {
+		best := targets[0]
+		for _, t := range targets {
+			if best.score < t.score {
+				best = t
 			}
-			found = true
+		}
+		found = true
+		switch best.kind {
+		case "item":
+			posToGo = best.pos
+			why = "item"
+		case "bomb":
+			why = fmt.Sprintf("bomb at (%d,%d) for %d boxes", best.pos.X, best.pos.Y, best.n)
 			if best.pos == origin {
 				posToGo = best.toGo
 				dropBomb = true
 			} else {
 				posToGo = best.pos
 			}
-			debug("bomb at %v with %d boxes", best.pos, n)
-
+			lg.info(tagBombs, "bomb", "pos", best.pos, "boxes", best.n)
 		}
+		lg.debug(tagStrategy, "best target", "kind", best.kind, "pos", best.pos, "score", best.score)
+	}
+
+	if outOfTime("bomb") {
+		return fallback
 	}
 
 	if !found {
-		debug("stay here? is it safe? let's find a safe place")
+		lg.debug(tagStrategy, "stay here? is it safe? let's find a safe place")
 
 		// 8턴을 살아남을 곳 찾아보자.
 		// 닥터 스트레인지처럼
 		// 각 경우를 따져보아야..
 		// 난 어디로 갈까?
 		// 상대는 어디로 갈까?
-		bt(myID, world{0, board, players, bombs, items})
+		// 1:1 이면 끝에서 duel 로 확인한다.
 
 		var bombsInDanger []Bomb
 		for _, b := range bombs {
-			if b.inRange(me.Pos) {
+			if rules.Kills && b.inRange(me.Pos) {
 				bombsInDanger = append(bombsInDanger, b)
 			}
 		}
 
 		if len(bombsInDanger) > 0 {
-			debug("need to escape from bombs")
+			lg.info(tagBombs, "need to escape from bombs", "n", len(bombsInDanger))
 			bfs(origin, bombs, items, func(x, y, d, x0, y0 int, bombs []Bomb, items []Item) bool {
 				safe := true
 				for _, b := range bombs {
-					if b.inRange(Pos{x, y}) {
+					if rules.Kills && b.inRange(Pos{x, y}) {
 						safe = false
 						break
 					}
@@ -774,6 +674,8 @@ func (r game) round() bool {
 				if safe {
 					found = true
 					posToGo = Pos3{x, y, d}
+					trace.consider("escape", posToGo, float64(d), "")
+					why = "escape"
 					return true
 				}
 				return false
@@ -781,25 +683,42 @@ func (r game) round() bool {
 		}
 	}
 
+	if outOfTime("escape") {
+		return fallback
+	}
+
 	// game engine just get shorted path
 	// but it can be dangerous
+	trace.Target = posToGo
+	trace.Path = origin.pathTo(posToGo, bombs)
 	posToGo = origin.safePathTo(posToGo, bombs)
+	if _, ok := me.canEscapeFrom(posToGo, bombs); ok {
+		fallback = action{pos: posToGo.Pos()}
+	}
 	if !dropBomb && me.Bombs > 0 {
-		debug("however, I  have a bomb")
-		ok, _, _ := me.canDropBomb(origin, bombs)
+		lg.trace(tagBombs, "however, I have a bomb")
+		ok, _, n := me.canDropBomb(origin, bombs)
 		if ok {
-			debug("with bomb drop, need to check if I can escape")
+			lg.trace(tagBombs, "with bomb drop, need to check if I can escape")
 			if posToGo.Z == 0 {
-				debug("yes can escape from %v, (already figured out)", posToGo)
+				lg.debug(tagBombs, "drop on the way, escape already figured out", "from", posToGo)
 				dropBomb = true
 			} else if _, ok := me.canEscapeFrom(posToGo, me.dropBomb(bombs)); ok {
-				debug("yes can escape from %v", posToGo)
+				lg.debug(tagBombs, "drop on the way", "from", posToGo)
 				dropBomb = true
 			} else {
-				debug("can't escape if i put a bomb here. so just moving!")
+				lg.debug(tagBombs, "can't escape if i put a bomb here. so just moving!")
+				trace.reject("bomb", origin, reasonNoEscape)
+			}
+			if dropBomb {
+				trace.considerFeatures("bomb", origin, eb.features(origin, n, itemBoxes(me.Pos, me.Range), 0), "on the way")
+				why += ", bomb on the way"
 			}
 		} else {
-			debug("noway, bomb'll kill me!")
+			lg.trace(tagBombs, "noway, bomb'll kill me!")
+			if n > 0 {
+				trace.reject("bomb", origin, reasonUnsafe)
+			}
 		}
 	}
 
@@ -818,30 +737,54 @@ func (r game) round() bool {
 	// 목적지로 가서 살수 있을까?
 	// 살수 없다면 거기로 가지말자.
 
-	if !surviveIfAllBombs(posToGo, dropBomb, bombs) {
-		debug("if others put bombs, I may die from %v", posToGo)
+	if outOfTime("drop") {
+		return fallback
+	}
+
+	// 폭탄에 죽지 않는 리그면 상대가 폭탄을 놓는 경우는 따질 필요가 없다.
+	if rules.Kills && !surviveIfAllBombs(posToGo, dropBomb, bombs) {
+		lg.info(tagBombs, "if others put bombs, I may die", "from", posToGo, "drop", dropBomb)
+		kind := "move"
+		if dropBomb {
+			kind = "bomb"
+		}
+		trace.reject(kind, posToGo, reasonTrap)
 		if dropBomb && surviveIfAllBombs(posToGo, false, bombs) {
-			debug("if i don't drop bomb, it's okay")
+			lg.info(tagBombs, "if i don't drop bomb, it's okay")
 			dropBomb = false
+			why = "fallback: move without bomb"
 		} else if dropBomb && surviveIfAllBombs(origin, dropBomb, bombs) {
-			debug("if can survive from origin with bomb")
+			lg.info(tagBombs, "survive from origin with bomb")
 			path, _ := me.canEscapeFrom(origin, allBombs(dropBomb, bombs))
-			posToGo = path[0]
+			posToGo = firstStep(path, origin)
+			why = "fallback: bomb and escape from origin"
 		} else if dropBomb && surviveIfAllBombs(origin, false, bombs) {
-			debug("if can survive from origin without bomb")
+			lg.info(tagBombs, "survive from origin without bomb")
 			path, _ := me.canEscapeFrom(origin, allBombs(false, bombs))
-			posToGo = path[0]
+			posToGo = firstStep(path, origin)
 			dropBomb = false
+			why = "fallback: escape from origin without bomb"
 		} else if surviveIfAllBombs(origin, false, bombs) {
-			debug("if can survive from origin")
+			lg.info(tagBombs, "survive from origin")
 			path, _ := me.canEscapeFrom(origin, allBombs(false, bombs))
-			posToGo = path[0]
+			posToGo = firstStep(path, origin)
+			why = "fallback: escape from origin"
 		} else {
-			debug("doomed!")
+			lg.warn(tagStrategy, "doomed!", "pos", origin)
+			why = "doomed"
 		}
 	}
 
-	r.move(dropBomb, posToGo.Pos())
+	if lg.enabled(tagStrategy, levelDebug) {
+		lg.debug(tagStrategy, "board\n"+strings.Join(plainRenderer.renderTrace(&trace), "\n"))
+	}
+	a := action{dropBomb, posToGo.Pos()}
+	if d, reason, ok := duel(a); ok {
+		lg.info(tagStrategy, "duel overrides", "from", a, "to", d)
+		a, why = d, reason
+	}
+	trace.choose(a, why)
+	return a
 
 	// 	// 이때 도망가는 중에도 폭탄을 떨어뜨릴지 고민해보자
 	// 	// 일단 도망
@@ -873,43 +816,6 @@ func (r game) round() bool {
 	//   피할 수 있는 곳이 bomb countdown 거리 내에 있나? 그럼 피하자
 	// range 바
 
-	return true
-}
-
-type world struct {
-	t       int
-	board   [][]int
-	players []Player
-	bombs   []Bomb
-	items   []Item
-}
-
-func (w world) String() string {
-	return ""
-}
-
-type move struct {
-}
-
-func (w world) next() []world {
-	return nil
-}
-
-func (w world) winner() int {
-	return -1
-}
-
-func bt(id int, w world) {
-	switch winner := w.winner(); winner {
-	case -1:
-		break
-	default:
-		return
-	}
-	// 각 player 의 next move 를 따라가보자.
-	for _, w := range w.next() {
-		bt(id, w)
-	}
 }
 
 func allBombs(dropBomb bool, bombs []Bomb) []Bomb {
@@ -920,8 +826,10 @@ func allBombs(dropBomb bool, bombs []Bomb) []Bomb {
 			}
 			continue
 		}
-		if p.Bombs > 0 {
-			bombs = p.dropBomb(bombs)
+		// 폭탄을 거의 안 놓는 상대는 빼준다.
+		// 지금 폭탄이 없으면 돌아오는 턴부터 놓을 수 있다고 본다.
+		if z, ok := bombBack(p, bombs); ok && rules.Kills && mayBomb(p) {
+			bombs = p.dropAround(z, bombs)
 		}
 	}
 	return bombs
@@ -941,7 +849,7 @@ func (p Player) canEscapeFrom(pos Pos3, bombs []Bomb) ([]Pos3, bool) {
 		// here := Pos3{x, y, d}
 		safe := true
 		for _, b := range bs {
-			if b.inRange(Pos{x, y}) {
+			if rules.Kills && b.inRange(Pos{x, y}) {
 				safe = false
 				break
 			}
@@ -978,16 +886,56 @@ func (p Player) canEscapeFrom(pos Pos3, bombs []Bomb) ([]Pos3, bool) {
 }
 
 func (p Player) dropBomb(bombs []Bomb) []Bomb {
-	b := Bomb{
-		Pos:       p.Pos,
+	return p.dropBombAt(0, bombs)
+}
+
+// dropBombAt 은 z 턴 뒤에 p 가 지금 자리에 폭탄을 놓은 것처럼 bombs 에 더한다.
+func (p Player) dropBombAt(z int, bombs []Bomb) []Bomb {
+	var bombs2 []Bomb
+	bombs2 = append(bombs2, bombs...)
+	bombs2 = append(bombs2, p.bombAt(p.Pos, z))
+	syncBombs(bombs2, board, items)
+	return bombs2
+}
+
+// bombAt 은 p 가 z 턴 뒤에 pos 에 놓는 폭탄
+func (p Player) bombAt(pos Pos, z int) Bomb {
+	return Bomb{
+		Pos:       pos,
 		Owner:     p.ID,
 		Range:     p.Range,
-		CountDown: 8 + 1,
+		CountDown: rules.BombTimer + 1 + z,
+		Placed:    z + 1,
 	}
+}
+
+// dropReach 는 상대가 몇 걸음 가서 폭탄을 놓는 것까지 보는지
+const dropReach = 1
 
+// dropAround 는 p 가 dropReach 걸음 안에 갈 수 있는 칸마다
+// 가장 빨리 놓을 수 있는 턴(걸음 수와 폭탄이 돌아오는 턴 z 중 늦은 것)에 폭탄을 놓은 것처럼 bombs 에 더한다.
+func (p Player) dropAround(z int, bombs []Bomb) []Bomb {
 	var bombs2 []Bomb
 	bombs2 = append(bombs2, bombs...)
-	bombs2 = append(bombs2, b)
+	layer := []Pos{p.Pos}
+	seen := SetPos{p.Pos: {}}
+	for d := 0; len(layer) > 0; d++ {
+		var next []Pos
+		for _, c := range layer {
+			bombs2 = append(bombs2, p.bombAt(c, max(z, d)))
+			if d == dropReach {
+				continue
+			}
+			for _, step := range []Pos{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+				to := Pos{c.X + step.X, c.Y + step.Y}
+				if !seen.has(to) && canGo(to.at(d+1), bombs) {
+					seen.add(to)
+					next = append(next, to)
+				}
+			}
+		}
+		layer = next
+	}
 	syncBombs(bombs2, board, items)
 	return bombs2
 }
@@ -1022,12 +970,7 @@ func (p Player) canDropBomb(pos Pos3, bombs []Bomb) (canDrop bool, safePlace Pos
 
 	bombs = removeOld(bombs, pos.Z)
 
-	b := Bomb{
-		Pos:       pos.Pos(),
-		Owner:     p.ID,
-		Range:     p.Range,
-		CountDown: 9 + pos.Z,
-	}
+	b := p.bombAt(pos.Pos(), pos.Z)
 
 	bombs2 := make([]Bomb, len(bombs))
 	copy(bombs2, bombs)
@@ -1046,7 +989,7 @@ func (p Player) canDropBomb(pos Pos3, bombs []Bomb) (canDrop bool, safePlace Pos
 	}
 	path, canDrop := p.canEscapeFrom(pos, bombs2)
 	if canDrop {
-		safePlace = path[0]
+		safePlace = firstStep(path, pos)
 	}
 	return
 }
@@ -1078,21 +1021,95 @@ func abs(a int) int {
 	return a
 }
 
+func manhattan(a, b Pos) int {
+	return abs(a.X-b.X) + abs(a.Y-b.Y)
+}
+
 type game struct {
-	io.Reader
+	in *parser
 	io.Writer
+	bot strategy
 }
 
 func main() {
-	var r io.Reader
 	if len(os.Args) > 1 {
-		r, _ = os.Open(os.Args[1])
-	} else {
-		r = os.Stdin
+		if cmd, ok := commands[os.Args[1]]; ok {
+			cmd(os.Args[2:])
+			return
+		}
+	}
+
+	cpuprofile := flag.String("cpuprofile", "", "write a cpu profile to `file`")
+	memprofile := flag.String("memprofile", "", "write a memory profile to `file`")
+	repeat := flag.Int("repeat", 1, "replay the transcript `n` times (for profiling)")
+	rulesFile := flag.String("rules", "", "load rule parameters from a JSON `file`")
+	flag.IntVar(&duelNodes, "nodes", 0, "stop the duel search after `n` nodes instead of on the clock (reproducible play)")
+	weightsFile := flag.String("weights", "", "load evaluation weights from a JSON `file` (default: built-in weights.json)")
+	league := flag.String("league", "auto", "league rules: auto, "+leagueNames())
+	botName := flag.String("bot", "main", "strategy to play: "+botNames())
+	seed := flag.Int64("seed", 1, "random `seed` for strategies that use one")
+	logSpec := flag.String("log", "", "log levels like `warn,strategy=debug` (env HS_LOG)")
+	flag.StringVar(&snapshotMode, "snapshot", snapshotMode, "per-turn input snapshot on stderr: off, text or gz64 (env HS_SNAPSHOT)")
+	flag.Parse()
+	if err := lg.configure(*logSpec); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if err := setLeague(*league); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *rulesFile != "" {
+		leagueAuto = false
+		r, err := loadRules(*rulesFile, rules)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		rules = r
+	}
+	if *weightsFile != "" {
+		w, err := loadWeights(*weightsFile, weights)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(2)
+		}
+		weights = w
+	}
+
+	bot, err := newStrategy(*botName, *seed)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
 	}
 
-	g := game{r, os.Stdout}
-	g.init()
+	stop := startProfile(*cpuprofile, *memprofile)
+	defer stop()
+
+	if flag.NArg() == 0 {
+		play(os.Stdin, os.Stdout, bot)
+		return
+	}
+	for i := 0; i < *repeat; i++ {
+		f, err := os.Open(flag.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return
+		}
+		if i > 0 {
+			lg.out = io.Discard
+		}
+		play(f, os.Stdout, bot)
+		f.Close()
+	}
+}
+
+func play(r io.Reader, w io.Writer, bot strategy) {
+	g := game{newParser(r), w, bot}
+	if err := g.init(); err != nil {
+		lg.warn(tagParser, "bad init", "err", err)
+		return
+	}
 	for {
 		on := g.round()
 		if !on {
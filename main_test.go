@@ -0,0 +1,100 @@
+package main
+
+import "testing"
+
+// openState 는 벽도 상자도 없는 w x h 보드에 me(0) 와 상대(1)를 놓은 상태
+func openState(w, h int, me, opp Player) *State {
+	s := &State{Width: w, Height: h, MyID: 0}
+	for y := 0; y < h; y++ {
+		row := make([]byte, w)
+		for x := range row {
+			row[x] = cellFloor
+		}
+		s.Board = append(s.Board, string(row))
+	}
+	for _, p := range []Player{me, opp} {
+		s.Entities = append(s.Entities, Entity{EntityPlayer, p.ID, p.Pos.X, p.Pos.Y, p.Bombs, p.Range})
+	}
+	return s
+}
+
+// TestFutureBombChain 은 앞으로 놓을 폭탄이 놓이기 전의 불길로 연쇄폭발하지 않는지 본다.
+func TestFutureBombChain(t *testing.T) {
+	openState(7, 1, Player{Pos{0, 0}, 0, 1, 3}, Player{Pos{6, 0}, 1, 0, 3}).apply()
+	opp := Player{Pos{3, 0}, 1, 0, 3}
+	tests := []struct {
+		name      string
+		countDown int // (2,0) 에 있는 폭탄. 불길이 (3,0) 에 닿는다
+		z         int // 상대가 (3,0) 에 폭탄을 놓는 턴
+		want      int // 놓을 폭탄의 CountDown
+	}{
+		{"placed after the blast", 3, 5, rules.BombTimer + 6},
+		{"placed in the blast turn", 3, 2, rules.BombTimer + 3},
+		{"placed before the blast", 4, 2, 4},
+		{"placed now", 2, 0, 2},
+		{"exploding now", 1, 0, rules.BombTimer + 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			bs := []Bomb{{Pos: Pos{2, 0}, Owner: 0, CountDown: tt.countDown, Range: 3}, opp.bombAt(opp.Pos, tt.z)}
+			syncBombs(bs, board, items)
+			if bs[1].CountDown != tt.want {
+				t.Errorf("CountDown %d, want %d", bs[1].CountDown, tt.want)
+			}
+		})
+	}
+}
+
+// TestFutureBombBlocks 는 앞으로 놓을 폭탄이 Placed 턴부터만 길을 막는지 본다.
+func TestFutureBombBlocks(t *testing.T) {
+	openState(5, 1, Player{Pos{0, 0}, 0, 1, 2}, Player{Pos{4, 0}, 1, 1, 2}).apply()
+	b := Player{Pos{4, 0}, 1, 1, 2}.bombAt(Pos{2, 0}, 3)
+	for z := 1; z < 8; z++ {
+		want := z < b.Placed
+		if got := canGo(Pos{2, 0}.at(z), []Bomb{b}); got != want {
+			t.Errorf("canGo at z=%d = %v, want %v", z, got, want)
+		}
+	}
+	// 놓이기 전에 지나가면 bfs 도 그 칸을 지난다.
+	var passed []int
+	bfs(me.Pos.at(0), []Bomb{b}, items, func(x, y, d, x0, y0 int, bs []Bomb, is []Item) bool {
+		if x == 2 {
+			passed = append(passed, d)
+		}
+		return false
+	})
+	for _, d := range passed {
+		if d >= b.Placed {
+			t.Errorf("bfs enters (2,0) at d=%d, bomb is placed at %d", d, b.Placed)
+		}
+	}
+	if len(passed) == 0 {
+		t.Error("bfs never enters (2,0)")
+	}
+}
+
+// TestDropAround 는 상대가 dropReach(1) 걸음 안에서 놓을 수 있는 폭탄들을 본다.
+func TestDropAround(t *testing.T) {
+	// range 1 이라서 서로 연쇄폭발하지 않는다.
+	openState(7, 7, Player{Pos{0, 0}, 0, 1, 2}, Player{Pos{3, 3}, 1, 0, 1}).apply()
+	opp := players[1]
+	tests := []struct {
+		z    int
+		want map[Pos]int // 칸마다 놓는 턴
+	}{
+		{0, map[Pos]int{{3, 3}: 0, {3, 2}: 1, {4, 3}: 1, {3, 4}: 1, {2, 3}: 1}},
+		{3, map[Pos]int{{3, 3}: 3, {3, 2}: 3, {4, 3}: 3, {3, 4}: 3, {2, 3}: 3}},
+	}
+	for _, tt := range tests {
+		bs := opp.dropAround(tt.z, nil)
+		if len(bs) != len(tt.want) {
+			t.Fatalf("z=%d: %d bombs, want %d: %v", tt.z, len(bs), len(tt.want), bs)
+		}
+		for _, b := range bs {
+			z, ok := tt.want[b.Pos]
+			if !ok || b.Placed != z+1 || b.CountDown != rules.BombTimer+1+z {
+				t.Errorf("z=%d: unexpected bomb %+v", tt.z, b)
+			}
+		}
+	}
+}
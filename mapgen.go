@@ -0,0 +1,142 @@
+//go:build !arena
+
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"math/rand"
+	"os"
+	"time"
+)
+
+// 공식 심판처럼 상자 수는 이 범위에서 고른다.
+const (
+	minBoxes = 30
+	maxBoxes = 65
+)
+
+// spawns 는 플레이어 id 순서대로 시작하는 모서리
+func spawns(w, h int) []Pos {
+	return []Pos{{0, 0}, {w - 1, h - 1}, {w - 1, 0}, {0, h - 1}}
+}
+
+// generated 는 만든 맵과 그 seed
+type generated struct {
+	Seed  int64
+	Boxes int
+	State *State
+}
+
+// genMap 은 seed 로 맵을 만든다. 같은 seed 와 규칙이면 항상 같은 맵이다.
+// 보드는 상하좌우 대칭이고, 홀수 (x, y) 에 기둥(X)이 있고,
+// 모서리 시작 자리와 그 옆 칸에는 상자를 두지 않는다.
+func genMap(seed int64, nPlayers int, myID int) generated {
+	rng := rand.New(rand.NewSource(seed))
+	w, h := rules.Width, rules.Height
+
+	cells := make([][]byte, h)
+	for y := range cells {
+		cells[y] = make([]byte, w)
+		for x := range cells[y] {
+			cells[y][x] = cellFloor
+			if x%2 == 1 && y%2 == 1 {
+				cells[y][x] = cellWall
+			}
+		}
+	}
+
+	// 왼쪽 위 1/4 만 정하고 나머지는 뒤집어서 채운다.
+	var quarter []Pos
+	for y := 0; y <= (h-1)/2; y++ {
+		for x := 0; x <= (w-1)/2; x++ {
+			if cells[y][x] != cellFloor || x+y <= 1 {
+				continue
+			}
+			quarter = append(quarter, Pos{x, y})
+		}
+	}
+	rng.Shuffle(len(quarter), func(i, j int) { quarter[i], quarter[j] = quarter[j], quarter[i] })
+
+	target := minBoxes + rng.Intn(maxBoxes-minBoxes+1)
+	boxes := 0
+	for _, p := range quarter {
+		if boxes >= target {
+			break
+		}
+		c := byte(cellBoxEmpty)
+		if rules.Items {
+			// 절반은 빈 상자, 나머지는 반반 아이템 상자
+			switch rng.Intn(4) {
+			case 0:
+				c = cellBoxRange
+			case 1:
+				c = cellBoxPlus
+			}
+		}
+		for _, m := range mirrors(p, w, h) {
+			if cells[m.Y][m.X] == cellFloor {
+				cells[m.Y][m.X] = c
+				boxes++
+			}
+		}
+	}
+
+	s := &State{Width: w, Height: h, MyID: myID}
+	for _, row := range cells {
+		s.Board = append(s.Board, string(row))
+	}
+	for id, p := range spawns(w, h)[:nPlayers] {
+		s.Entities = append(s.Entities, Entity{EntityPlayer, id, p.X, p.Y, rules.StartBombs, rules.StartRange})
+	}
+	return generated{seed, boxes, s}
+}
+
+// mirrors 는 p 와 대칭인 칸들 (가운데 줄에 있으면 겹친다)
+func mirrors(p Pos, w, h int) []Pos {
+	return []Pos{p, {w - 1 - p.X, p.Y}, {p.X, h - 1 - p.Y}, {w - 1 - p.X, h - 1 - p.Y}}
+}
+
+// write 는 input.txt 처럼 seed 를 메모로 남기고 첫 턴을 쓴다.
+func (g generated) write(w io.Writer) error {
+	if _, err := fmt.Fprintf(w, "-- seed=%d\n-- boxes=%d\n", g.Seed, g.Boxes); err != nil {
+		return err
+	}
+	if err := g.State.WriteInit(w); err != nil {
+		return err
+	}
+	return g.State.WriteProtocol(w)
+}
+
+// genmapCommand 는 랜덤 맵을 만든다.
+//
+//	hypersonic genmap [-seed n] [-n count] [-players 2|3|4] [-id myId] [-league name]
+func genmapCommand(args []string) {
+	fs := flag.NewFlagSet("genmap", flag.ExitOnError)
+	seed := fs.Int64("seed", 0, "first `seed` (0 picks one from the clock)")
+	n := fs.Int("n", 1, "number of maps; map i uses seed+i")
+	nPlayers := fs.Int("players", 2, "number of players (2-4)")
+	id := fs.Int("id", 0, "myId written in the init line")
+	league := fs.String("league", "bronze", "league rules: "+leagueNames())
+	fs.Parse(args)
+
+	if err := setLeague(*league); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *nPlayers < 2 || *nPlayers > maxPlayers || *id < 0 || *id >= *nPlayers {
+		fmt.Fprintln(os.Stderr, "genmap: bad -players or -id")
+		os.Exit(2)
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano() % 1e9
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	for i := 0; i < *n; i++ {
+		genMap(*seed+int64(i), *nPlayers, *id).write(w)
+	}
+}
@@ -0,0 +1,170 @@
+//go:build !arena
+
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// bot 은 로컬 대전에서 자리 하나를 맡는다.
+type bot interface {
+	// turn 은 이번 턴 입력을 주고 출력 한 줄을 받는다.
+	turn(s *State) (string, error)
+	close() error
+}
+
+// seatResult 는 한 판에서 자리 하나의 결과
+type seatResult struct {
+	Rank      int    `json:"rank"`
+	Boxes     int    `json:"boxes"`
+	Death     string `json:"death,omitempty"`
+	DeathTurn int    `json:"deathTurn,omitempty"`
+}
+
+// matchResult 는 한 판의 결과. Seats 는 플레이어 id 순서다.
+type matchResult struct {
+	Seed  int64        `json:"seed"`
+	Turns int          `json:"turns"`
+	Seats []seatResult `json:"seats"`
+}
+
+// playMatch 는 맵 g 에서 bots 끼리 한 판 한다. bots[i] 가 i 번 플레이어다.
+// 봇들은 매 턴 동시에 생각한다. 시간을 넘기거나 죽은 봇은 그 턴에 탈락한다.
+// watch 가 있으면 매 턴 출력을 받은 뒤, 진행하기 전에 부른다.
+func playMatch(g generated, bots []bot, watch func(rf *referee, cmds []string)) matchResult {
+	rf := newReferee(g, rules)
+	for !rf.over() {
+		cmds := make([]string, len(bots))
+		errs := make([]error, len(bots))
+		var wg sync.WaitGroup
+		for i, p := range rf.players {
+			if !p.Alive {
+				continue
+			}
+			wg.Add(1)
+			go func(i int) {
+				defer wg.Done()
+				cmds[i], errs[i] = bots[i].turn(rf.state(i))
+			}(i)
+		}
+		wg.Wait()
+		for i, err := range errs {
+			if err == nil {
+				continue
+			}
+			cause := deathCrash
+			if errors.Is(err, errBotTimeout) {
+				cause = deathTimeout
+			}
+			lg.info(tagReferee, "bot out", "id", i, "turn", rf.turn+1, "err", err)
+			rf.forfeit(i, cause)
+		}
+		if watch != nil {
+			watch(rf, cmds)
+		}
+		rf.step(cmds)
+	}
+
+	result := matchResult{Seed: g.Seed, Turns: rf.turn}
+	for i, rank := range rf.ranks() {
+		p := rf.players[i]
+		result.Seats = append(result.Seats, seatResult{rank, p.Boxes, p.Death, p.DeathTurn})
+	}
+	return result
+}
+
+func (m matchResult) String() string {
+	var parts []string
+	for i, s := range m.Seats {
+		part := fmt.Sprintf("p%d #%d boxes=%d", i, s.Rank, s.Boxes)
+		if s.Death != "" {
+			part += fmt.Sprintf(" died=%q@%d", s.Death, s.DeathTurn)
+		}
+		parts = append(parts, part)
+	}
+	return fmt.Sprintf("seed=%d turns=%d %s", m.Seed, m.Turns, strings.Join(parts, ", "))
+}
+
+// matchCommand 는 외부 봇들로 한 판 한다. 명령 하나가 봇 하나다.
+//
+//	hypersonic match [-seed n] [-o transcript] "./hypersonic" "python3 other.py" ...
+func matchCommand(args []string) {
+	fs := flag.NewFlagSet("match", flag.ExitOnError)
+	seed := fs.Int64("seed", 0, "map `seed` (0 picks one from the clock)")
+	league := fs.String("league", "bronze", "league rules: "+leagueNames())
+	out := fs.String("o", "", "write the game as seen by -view to a transcript `file`")
+	view := fs.Int("view", 0, "player id whose input goes to -o")
+	verbose := fs.Bool("v", false, "print every turn's outputs")
+	lim := officialLimits
+	lim.addFlags(fs)
+	fs.Parse(args)
+
+	if err := setLeague(*league); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	n := fs.NArg()
+	if n < 2 || n > maxPlayers || *view < 0 || *view >= n {
+		fmt.Fprintln(os.Stderr, "match: want 2 to 4 bot commands and a valid -view")
+		os.Exit(2)
+	}
+	if *seed == 0 {
+		*seed = time.Now().UnixNano() % 1e9
+	}
+
+	if err := runMatch(fs.Args(), *seed, lim, *out, *view, *verbose); err != nil {
+		fmt.Fprintln(os.Stderr, "match:", err)
+		os.Exit(1)
+	}
+}
+
+// runMatch 는 봇들을 띄워서 한 판 하고 결과를 쓴다.
+// 에러로 끝나도 띄운 봇들은 모두 닫는다.
+func runMatch(commands []string, seed int64, lim limits, out string, view int, verbose bool) error {
+	var bots []bot
+	defer func() {
+		for _, b := range bots {
+			b.close()
+		}
+	}()
+	for _, command := range commands {
+		b, err := startBot(command, lim)
+		if err != nil {
+			return err
+		}
+		bots = append(bots, b)
+	}
+
+	var states []*State
+	result := playMatch(genMap(seed, len(bots), 0), bots, func(rf *referee, cmds []string) {
+		if out != "" {
+			states = append(states, rf.state(view))
+		}
+		if verbose {
+			fmt.Printf("turn %d: %s\n", rf.turn+1, strings.Join(cmds, " | "))
+		}
+	})
+	for i, b := range bots {
+		if err := b.(*procBot).err; err != nil {
+			fmt.Fprintf(os.Stderr, "p%d: %v\n", i, err)
+		}
+	}
+	fmt.Println(result)
+
+	if out == "" {
+		return nil
+	}
+	f, err := os.Create(out)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	fmt.Fprintf(f, "-- seed=%d\n", seed)
+	return writeTranscript(f, states)
+}
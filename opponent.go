@@ -0,0 +1,233 @@
+package main
+
+// opponentModel 은 상대 하나가 이번 게임에서 어떻게 움직였는지 센다.
+// 모든 비율은 (본 횟수+1)/(기회+2) 로 계산해서 처음에는 반반으로 시작한다.
+type opponentModel struct {
+	ID int
+
+	// 폭탄을 놓을 수 있던 턴 / 실제로 놓은 턴.
+	// 놓으면 상자나 플레이어에 닿는 자리(useful)와 아닌 자리(idle)를 따로 센다.
+	usefulChances, usefulBombs int
+	idleChances, idleBombs     int
+	itemChances, chased        int // 가까이 아이템이 있던 턴 / 그쪽으로 다가간 턴
+	meChances, closer          int // 나와의 거리가 바뀔 수 있던 턴 / 가까워진 턴
+	turns, stays               int // 본 턴 / 제자리에 있던 턴
+}
+
+// itemSight 는 아이템을 쫓는지 볼 때 얼마나 가까운 아이템까지 보는지
+const itemSight = 4
+
+// threatProb 보다 폭탄을 덜 놓는 상대는 safety check 에서 폭탄을 놓는다고 보지 않는다.
+const threatProb = 0.1
+
+var opponents = map[int]*opponentModel{}
+
+func rate(n, chances int) float64 {
+	return float64(n+1) / float64(chances+2)
+}
+
+// bombRate 는 폭탄을 놓을 수 있을 때 놓는 비율. useful 은 놓으면 뭔가 맞는 자리인지
+func (m *opponentModel) bombRate(useful bool) float64 {
+	if useful {
+		return rate(m.usefulBombs, m.usefulChances)
+	}
+	return rate(m.idleBombs, m.idleChances)
+}
+
+func (m *opponentModel) itemRate() float64  { return rate(m.chased, m.itemChances) }
+func (m *opponentModel) closeRate() float64 { return rate(m.closer, m.meChances) }
+func (m *opponentModel) stayRate() float64  { return rate(m.stays, m.turns) }
+
+// opponent 는 id 의 모델. 없으면 만든다.
+func opponent(id int) *opponentModel {
+	m := opponents[id]
+	if m == nil {
+		m = &opponentModel{ID: id}
+		opponents[id] = m
+	}
+	return m
+}
+
+// updateOpponents 는 지난 턴 prev 에서 이번 턴 s 로 바뀐 것을 보고 모델들을 고친다.
+// 새 게임이면 (game 이 바뀌면) 처음부터 센다.
+func updateOpponents(prev, s *State, newGame bool) {
+	if newGame {
+		opponents = map[int]*opponentModel{}
+	}
+	if prev == nil {
+		return
+	}
+	now := map[int]Player{}
+	for _, p := range s.Players() {
+		now[p.ID] = p
+	}
+	var mePrev Player
+	for _, p := range prev.Players() {
+		if p.ID == s.MyID {
+			mePrev = p
+		}
+	}
+	// newBomb 은 새로 놓인 폭탄 주인 id+1 (없으면 0)
+	newBomb := map[Pos]int{}
+	for _, b := range s.Bombs() {
+		if b.CountDown == rules.BombTimer {
+			newBomb[b.Pos] = b.Owner + 1
+		}
+	}
+	players := prev.Players()
+	bb := newBitBoard(prev.grid(), prev.Bombs(), prev.Items())
+	prevBomb := map[Pos]bool{}
+	for _, b := range prev.Bombs() {
+		prevBomb[b.Pos] = true
+	}
+
+	for _, p := range prev.Players() {
+		cur, ok := now[p.ID]
+		if p.ID == s.MyID || !ok {
+			continue
+		}
+		m := opponent(p.ID)
+		m.turns++
+		if cur.Pos == p.Pos {
+			m.stays++
+		}
+		if p.Bombs > 0 && !prevBomb[p.Pos] {
+			dropped := newBomb[p.Pos] == p.ID+1
+			if bombUseful(&bb, p, players) {
+				m.usefulChances++
+				if dropped {
+					m.usefulBombs++
+				}
+			} else {
+				m.idleChances++
+				if dropped {
+					m.idleBombs++
+				}
+			}
+		}
+		if item, ok := nearestItem(p.Pos, prev.Items()); ok {
+			m.itemChances++
+			if manhattan(cur.Pos, item) < manhattan(p.Pos, item) {
+				m.chased++
+			}
+		}
+		if cur.Pos != p.Pos {
+			m.meChances++
+			// 내가 움직인 것과 상관없이 지난 턴의 내 자리를 기준으로 본다.
+			if manhattan(cur.Pos, mePrev.Pos) < manhattan(p.Pos, mePrev.Pos) {
+				m.closer++
+			}
+		}
+	}
+}
+
+// bombUseful 은 p 가 제자리에 폭탄을 놓으면 상자나 다른 플레이어에 닿는지
+func bombUseful(bb *bitBoard, p Player, players []Player) bool {
+	fire := bb.blast(p.Pos, p.Range)
+	if !fire.and(bb.allBoxes()).isZero() {
+		return true
+	}
+	for _, o := range players {
+		if o.ID != p.ID && fire.has(geo.index(o.Pos)) {
+			return true
+		}
+	}
+	return false
+}
+
+func nearestItem(from Pos, items []Item) (Pos, bool) {
+	best, found := Pos{}, false
+	for _, it := range items {
+		if d := manhattan(from, it.Pos); d <= itemSight && (!found || d < manhattan(from, best)) {
+			best, found = it.Pos, true
+		}
+	}
+	return best, found
+}
+
+// actionProb 는 상대의 다음 행동 하나와 그 확률
+type actionProb struct {
+	action
+	P float64
+}
+
+// predict 는 p 의 다음 행동 분포. 움직일 수 있는 칸(제자리 포함)마다
+// 폭탄을 놓는 경우와 안 놓는 경우로 나누고, 합이 1 이 되게 한다.
+// 지금 전역 상태(board, bombs, items, me)를 본다. duel 이 상대의 첫 수를 고르는 데 쓴다.
+func (m *opponentModel) predict(p Player) []actionProb {
+	item, hasItem := nearestItem(p.Pos, items)
+	type move struct {
+		to Pos
+		w  float64
+	}
+	moves := []move{{p.Pos, m.stayRate()}}
+	for _, step := range []Pos{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+		to := Pos{p.Pos.X + step.X, p.Pos.Y + step.Y}
+		if !canGo(to.at(1), bombs) {
+			continue
+		}
+		w := 1 - m.stayRate()
+		if hasItem && manhattan(to, item) < manhattan(p.Pos, item) {
+			w *= 2 * m.itemRate()
+		}
+		if manhattan(to, me.Pos) < manhattan(p.Pos, me.Pos) {
+			w *= 2 * m.closeRate()
+		} else {
+			w *= 2 * (1 - m.closeRate())
+		}
+		moves = append(moves, move{to, w})
+	}
+
+	pBomb := 0.0
+	if p.Bombs > 0 {
+		bb := newBitBoard(board, bombs, items)
+		pBomb = m.bombRate(bombUseful(&bb, p, players))
+		for _, b := range bombs {
+			if b.Pos == p.Pos {
+				pBomb = 0
+			}
+		}
+	}
+
+	total := 0.0
+	for _, mv := range moves {
+		total += mv.w
+	}
+	var result []actionProb
+	for _, mv := range moves {
+		pm := mv.w / total
+		if pBomb > 0 {
+			result = append(result, actionProb{action{true, mv.to}, pm * pBomb})
+		}
+		if pBomb < 1 {
+			result = append(result, actionProb{action{false, mv.to}, pm * (1 - pBomb)})
+		}
+	}
+	return result
+}
+
+// mayBomb 은 safety check 에서 p 가 폭탄을 놓는다고 볼지.
+// 분포 전체가 아니라 폭탄을 놓는 비율만 threatProb 와 비교한다.
+// 놓아도 아무것도 안 맞는 자리에서 폭탄을 거의 안 놓는 상대만 뺀다.
+// 지금 폭탄이 있는지는 보지 않는다. (bombBack 을 본다)
+func mayBomb(p Player) bool {
+	bb := newBitBoard(board, bombs, items)
+	return opponent(p.ID).bombRate(bombUseful(&bb, p, players)) >= threatProb
+}
+
+// bombBack 은 p 가 몇 턴 뒤부터 폭탄을 놓을 수 있는지. 지금 있으면 0.
+// 없으면 p 의 폭탄 중 가장 먼저 터지는 것이 돌아오는 턴이다.
+// canDropBomb 처럼 CountDown 이 z 이하인 폭탄은 z 에 돌아와 있다고 본다.
+// bombs 는 syncBombs 된 것이어야 연쇄로 일찍 터지는 것도 센다.
+func bombBack(p Player, bombs []Bomb) (int, bool) {
+	if p.Bombs > 0 {
+		return 0, true
+	}
+	z, ok := 0, false
+	for _, b := range bombs {
+		if b.Owner == p.ID && (!ok || b.CountDown < z) {
+			z, ok = b.CountDown, true
+		}
+	}
+	return z, ok
+}
@@ -0,0 +1,77 @@
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// TestPredict 는 상대의 행동 분포가 합이 1 이고,
+// 폭탄이 없거나 발밑에 폭탄이 있으면 폭탄 수가 없는지 본다.
+func TestPredict(t *testing.T) {
+	models := []struct {
+		name string
+		m    opponentModel
+	}{
+		{"new", opponentModel{}},
+		{"bomber", opponentModel{usefulChances: 10, usefulBombs: 10, idleChances: 10, idleBombs: 9}},
+		{"camper", opponentModel{turns: 20, stays: 19, meChances: 10, closer: 0}},
+		{"chaser", opponentModel{itemChances: 10, chased: 10, meChances: 10, closer: 10}},
+	}
+	for _, s := range testStates(t) {
+		s.apply()
+		for _, p := range players {
+			if p.ID == myID {
+				continue
+			}
+			for _, tt := range models {
+				dist := tt.m.predict(p)
+				total := 0.0
+				for _, ap := range dist {
+					if ap.P < 0 {
+						t.Fatalf("%s: negative probability %v", tt.name, ap)
+					}
+					if ap.bomb && (p.Bombs == 0 || isBomb(p.Pos)) {
+						t.Fatalf("%s: %v can not bomb: %v", tt.name, p, ap)
+					}
+					total += ap.P
+				}
+				if math.Abs(total-1) > 1e-9 {
+					t.Fatalf("%s: probabilities of %v sum to %v: %v", tt.name, p, total, dist)
+				}
+			}
+		}
+	}
+}
+
+// TestUpdateOpponents 는 한 턴 동안 상대가 움직인 것을 모델에 어떻게 세는지 본다.
+// 나와 가까워졌는지는 지난 턴의 내 자리를 기준으로 한다.
+func TestUpdateOpponents(t *testing.T) {
+	tests := []struct {
+		name      string
+		prev, cur *State
+		want      opponentModel
+	}{
+		{"I move, they stand still",
+			turnState(".......", playerAt(0, 2), playerAt(1, 5)),
+			turnState(".......", playerAt(0, 3), playerAt(1, 5)),
+			opponentModel{ID: 1, turns: 1, stays: 1, idleChances: 1}},
+		{"they come while I back off",
+			turnState(".......", playerAt(0, 2), playerAt(1, 5)),
+			turnState(".......", playerAt(0, 1), playerAt(1, 4)),
+			opponentModel{ID: 1, turns: 1, idleChances: 1, meChances: 1, closer: 1}},
+		{"they back off while I come",
+			turnState(".......", playerAt(0, 2), playerAt(1, 5)),
+			turnState(".......", playerAt(0, 3), playerAt(1, 6)),
+			opponentModel{ID: 1, turns: 1, idleChances: 1, meChances: 1}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			setLayout(tt.prev.Width, tt.prev.Height)
+			updateOpponents(nil, tt.prev, true)
+			updateOpponents(tt.prev, tt.cur, false)
+			if got := *opponents[1]; got != tt.want {
+				t.Errorf("model %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,303 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+)
+
+// 입력 오류의 종류
+var (
+	ErrSyntax = errors.New("syntax error")
+	ErrRange  = errors.New("value out of range")
+	ErrCount  = errors.New("wrong count")
+)
+
+const maxPlayers = 4
+
+// ParseError 는 입력의 몇번째 줄, 어느 필드가 잘못됐는지 알려준다.
+type ParseError struct {
+	Line  int
+	Field string
+	Text  string
+	Err   error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("line %d: %s: %v: %q", e.Line, e.Field, e.Err, e.Text)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// parser 는 심판이 주는 입력을 줄 단위로 읽는다.
+// 빈 줄과 "--" 로 시작하는 줄(트랜스크립트의 메모)은 건너뛴다.
+// 턴 시작 자리에 "W H myId" 줄이 오면 새 게임으로 보고 다시 init 한다.
+type parser struct {
+	sc       *bufio.Scanner
+	line     int
+	text     string
+	unread   bool
+	width    int
+	height   int
+	myID     int
+	turnLine int
+	// games 는 지금까지 읽은 init 줄 수 (새 게임마다 늘어난다)
+	games int
+
+	// onTurn 은 턴의 첫 줄을 읽자마자 부른다. (턴 타이머 시작용)
+	onTurn func()
+}
+
+func newParser(r io.Reader) *parser {
+	return &parser{sc: bufio.NewScanner(r)}
+}
+
+// next 는 다음 의미있는 줄을 돌려준다. 끝이면 io.EOF
+func (p *parser) next() (string, error) {
+	if p.unread {
+		p.unread = false
+		return p.text, nil
+	}
+	for p.sc.Scan() {
+		p.line++
+		text := strings.TrimSpace(p.sc.Text())
+		if text == "" || strings.HasPrefix(text, "--") {
+			continue
+		}
+		p.text = text
+		return text, nil
+	}
+	if err := p.sc.Err(); err != nil {
+		return "", err
+	}
+	return "", io.EOF
+}
+
+func (p *parser) back() {
+	p.unread = true
+}
+
+func (p *parser) errorf(field string, err error) error {
+	return &ParseError{Line: p.line, Field: field, Text: p.text, Err: err}
+}
+
+// unexpected 는 턴 중간에 입력이 끊긴 경우
+func (p *parser) unexpected(field string, err error) error {
+	if err == io.EOF {
+		err = io.ErrUnexpectedEOF
+	}
+	return &ParseError{Line: p.line, Field: field, Err: err}
+}
+
+// ints 는 한 줄을 n 개의 정수로 읽는다.
+func (p *parser) ints(field string, text string, n int) ([]int, error) {
+	fields := strings.Fields(text)
+	if len(fields) != n {
+		return nil, p.errorf(field, fmt.Errorf("%w: want %d fields, got %d", ErrSyntax, n, len(fields)))
+	}
+	values := make([]int, n)
+	for i, f := range fields {
+		v, err := strconv.Atoi(f)
+		if err != nil {
+			return nil, p.errorf(field, fmt.Errorf("%w: %q is not a number", ErrSyntax, f))
+		}
+		values[i] = v
+	}
+	return values, nil
+}
+
+func (p *parser) check(field string, v, lo, hi int) error {
+	if v < lo || v > hi {
+		return p.errorf(field, fmt.Errorf("%w: %d not in [%d, %d]", ErrRange, v, lo, hi))
+	}
+	return nil
+}
+
+func isHeader(text string) bool {
+	return len(strings.Fields(text)) == 3
+}
+
+// readInit 은 "width height myId" 줄을 읽는다.
+func (p *parser) readInit() error {
+	text, err := p.next()
+	if err != nil {
+		return err
+	}
+	return p.init(text)
+}
+
+func (p *parser) init(text string) error {
+	v, err := p.ints("init", text, 3)
+	if err != nil {
+		return err
+	}
+	if err := p.check("width", v[0], 1, bbBits); err != nil {
+		return err
+	}
+	if err := p.check("height", v[1], 1, bbBits/v[0]-1); err != nil {
+		return err
+	}
+	if err := p.check("myId", v[2], 0, maxPlayers-1); err != nil {
+		return err
+	}
+	p.width, p.height, p.myID = v[0], v[1], v[2]
+	p.games++
+	return nil
+}
+
+func validCell(c byte) bool {
+	switch c {
+	case cellFloor, cellWall, cellBoxEmpty, cellBoxRange, cellBoxPlus:
+		return true
+	}
+	return false
+}
+
+func (p *parser) isRow(text string) bool {
+	if len(text) != p.width {
+		return false
+	}
+	for i := 0; i < len(text); i++ {
+		if !validCell(text[i]) {
+			return false
+		}
+	}
+	return true
+}
+
+// readTurn 은 보드와 entity 들을 읽는다.
+// 턴 경계에서 입력이 끝나면 io.EOF 를, 중간에 끝나면 io.ErrUnexpectedEOF 를 감싼 ParseError 를 돌려준다.
+func (p *parser) readTurn() (*State, error) {
+	text, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	if isHeader(text) {
+		if err := p.init(text); err != nil {
+			return nil, err
+		}
+		if text, err = p.next(); err != nil {
+			return nil, p.unexpected("row 0", err)
+		}
+	}
+	p.turnLine = p.line
+	if p.onTurn != nil {
+		p.onTurn()
+	}
+
+	s := &State{Width: p.width, Height: p.height, MyID: p.myID}
+	for y := 0; y < p.height; y++ {
+		field := fmt.Sprintf("row %d", y)
+		if y > 0 {
+			if text, err = p.next(); err != nil {
+				return nil, p.unexpected(field, err)
+			}
+		}
+		if len(text) != p.width {
+			return nil, p.errorf(field, fmt.Errorf("%w: want %d cells, got %d", ErrCount, p.width, len(text)))
+		}
+		for x := 0; x < len(text); x++ {
+			if !validCell(text[x]) {
+				return nil, p.errorf(field, fmt.Errorf("%w: bad cell %q at x=%d", ErrSyntax, text[x], x))
+			}
+		}
+		s.Board = append(s.Board, text)
+	}
+
+	if text, err = p.next(); err != nil {
+		return nil, p.unexpected("entities", err)
+	}
+	v, err := p.ints("entities", text, 1)
+	if err != nil {
+		return nil, err
+	}
+	n := v[0]
+	if err := p.check("entities", n, 0, p.width*p.height+maxPlayers); err != nil {
+		return nil, err
+	}
+
+	seen := map[int]bool{}
+	for i := 0; i < n; i++ {
+		field := fmt.Sprintf("entity %d", i)
+		if text, err = p.next(); err != nil {
+			return nil, p.unexpected(field, err)
+		}
+		if p.isRow(text) || isHeader(text) {
+			// 다음 턴이 시작됐다. 개수가 모자란다.
+			p.back()
+			return nil, p.errorf(field, fmt.Errorf("%w: want %d entities, got %d", ErrCount, n, i))
+		}
+		e, err := p.entity(field, text)
+		if err != nil {
+			return nil, err
+		}
+		if e.Type == EntityPlayer {
+			if seen[e.Owner] {
+				return nil, p.errorf(field, fmt.Errorf("%w: player %d twice", ErrCount, e.Owner))
+			}
+			seen[e.Owner] = true
+		}
+		s.Entities = append(s.Entities, e)
+	}
+	return s, nil
+}
+
+type bounds struct {
+	name   string
+	v      int
+	lo, hi int
+}
+
+func (p *parser) entity(field string, text string) (Entity, error) {
+	v, err := p.ints(field, text, 6)
+	if err != nil {
+		return Entity{}, err
+	}
+	e := Entity{v[0], v[1], v[2], v[3], v[4], v[5]}
+	checks := []bounds{
+		{"entityType", e.Type, EntityPlayer, EntityItem},
+		{"x", e.X, 0, p.width - 1},
+		{"y", e.Y, 0, p.height - 1},
+	}
+	switch e.Type {
+	case EntityPlayer:
+		checks = append(checks,
+			bounds{"owner", e.Owner, 0, maxPlayers - 1},
+			bounds{"bombs", e.Param1, 0, p.width * p.height},
+			bounds{"range", e.Param2, 1, p.width + p.height})
+	case EntityBomb:
+		checks = append(checks,
+			bounds{"owner", e.Owner, 0, maxPlayers - 1},
+			bounds{"countdown", e.Param1, 1, rules.BombTimer},
+			bounds{"range", e.Param2, 1, p.width + p.height})
+	case EntityItem:
+		checks = append(checks,
+			bounds{"itemType", e.Param1, itemExtraRange, itemExtraBomb})
+	}
+	for _, c := range checks {
+		if err := p.check(field+" "+c.name, c.v, c.lo, c.hi); err != nil {
+			return Entity{}, err
+		}
+	}
+	return e, nil
+}
+
+// resync 는 잘못된 줄 다음부터 보드 줄처럼 생긴 곳(또는 새 게임 줄)까지 건너뛴다.
+// 다음 readTurn 은 거기서부터 읽는다.
+func (p *parser) resync() error {
+	for {
+		text, err := p.next()
+		if err != nil {
+			return err
+		}
+		if p.isRow(text) || isHeader(text) {
+			p.back()
+			return nil
+		}
+	}
+}
@@ -0,0 +1,80 @@
+package main
+
+import (
+	"errors"
+	"io"
+	"strings"
+	"testing"
+)
+
+func TestParser(t *testing.T) {
+	const turn = "...\n.X.\n...\n1\n0 0 0 0 1 3\n"
+	tests := []struct {
+		name   string
+		input  string
+		states int    // 읽은 턴 수
+		err    error  // 건너뛴 턴의 에러 종류 (nil 이면 에러 없음)
+		line   int    // 첫 에러의 줄
+		field  string // 첫 에러의 필드
+	}{
+		{"ok", "3 3 0\n" + turn + turn, 2, nil, 0, ""},
+		{"memo", "3 3 0\n-- seed 1\n\n" + turn + "-- next\n" + turn, 2, nil, 0, ""},
+		{"new game", "3 3 0\n" + turn + "1 1 0\n.\n0\n", 2, nil, 0, ""},
+		{"bad cell", "3 3 0\n...\n.Z.\n...\n0\n" + turn, 1, ErrSyntax, 3, "row 1"},
+		{"short row", "3 3 0\n...\n..\n...\n0\n" + turn, 1, ErrCount, 3, "row 1"},
+		{"not a number", "3 3 0\n...\n.X.\n...\n1\n0 0 a 0 1 3\n" + turn, 1, ErrSyntax, 6, "entity 0"},
+		{"fields", "3 3 0\n...\n.X.\n...\n1\n0 0 0 0 1\n" + turn, 1, ErrSyntax, 6, "entity 0"},
+		{"x range", "3 3 0\n...\n.X.\n...\n1\n0 0 3 0 1 3\n" + turn, 1, ErrRange, 6, "entity 0 x"},
+		{"countdown range", "3 3 0\n...\n.X.\n...\n1\n1 0 0 0 9 3\n" + turn, 1, ErrRange, 6, "entity 0 countdown"},
+		{"item type", "3 3 0\n...\n.X.\n...\n1\n2 0 0 0 3 0\n" + turn, 1, ErrRange, 6, "entity 0 itemType"},
+		{"too few entities", "3 3 0\n...\n.X.\n...\n2\n0 0 0 0 1 3\n" + turn, 1, ErrCount, 7, "entity 1"},
+		{"player twice", "3 3 0\n...\n.X.\n...\n2\n0 0 0 0 1 3\n0 0 1 0 1 3\n" + turn, 1, ErrCount, 7, "entity 1"},
+		{"truncated", "3 3 0\n" + turn + "...\n.X.\n", 1, io.ErrUnexpectedEOF, 8, "row 2"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			states, err := readProtocolStates(strings.NewReader(tt.input))
+			if len(states) != tt.states {
+				t.Errorf("read %d states, want %d", len(states), tt.states)
+			}
+			if tt.err == nil {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if !errors.Is(err, tt.err) {
+				t.Fatalf("error %v, want %v", err, tt.err)
+			}
+			var pe *ParseError
+			if !errors.As(err, &pe) {
+				t.Fatalf("error %v is not a ParseError", err)
+			}
+			if pe.Line != tt.line || pe.Field != tt.field {
+				t.Errorf("error at line %d %q, want line %d %q", pe.Line, pe.Field, tt.line, tt.field)
+			}
+		})
+	}
+}
+
+func TestParserInit(t *testing.T) {
+	tests := []struct {
+		input string
+		err   error
+	}{
+		{"13 11 0", nil},
+		{"13 11 3", nil},
+		{"13 11 4", ErrRange},
+		{"0 11 0", ErrRange},
+		{"13 100 0", ErrRange},
+		{"13 11", ErrSyntax},
+		{"", io.EOF},
+		{"13 x 0", ErrSyntax},
+	}
+	for _, tt := range tests {
+		err := newParser(strings.NewReader(tt.input)).readInit()
+		if tt.err == nil && err != nil || !errors.Is(err, tt.err) {
+			t.Errorf("readInit(%q) = %v, want %v", tt.input, err, tt.err)
+		}
+	}
+}
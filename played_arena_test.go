@@ -0,0 +1,10 @@
+//go:build arena
+
+package main
+
+import "testing"
+
+// testStates 는 input.txt 의 상태들
+func testStates(t testing.TB) []*State {
+	return inputStates(t)
+}
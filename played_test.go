@@ -0,0 +1,50 @@
+//go:build !arena
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+)
+
+// playedStates 는 seed 로 만든 맵에서 모두가 무작위로 두는 게임을 turns 턴까지 하면서
+// 0 번 플레이어가 받는 상태들을 모은다. 0 번이 죽으면 멈춘다.
+func playedStates(seed int64, nPlayers, turns int) []*State {
+	rf := newReferee(genMap(seed, nPlayers, 0), rules)
+	rng := rand.New(rand.NewSource(seed))
+	var states []*State
+	for len(states) < turns && !rf.over() && rf.players[0].Alive {
+		states = append(states, rf.state(0))
+		rf.step(randomCommands(rng, rf))
+	}
+	return states
+}
+
+// randomCommands 는 살아있는 플레이어마다 옆 칸이나 제자리로 가는 명령. 넷에 하나는 폭탄을 놓는다.
+func randomCommands(rng *rand.Rand, rf *referee) []string {
+	dirs := []Pos{{0, 0}, {0, -1}, {0, 1}, {-1, 0}, {1, 0}}
+	cmds := make([]string, len(rf.players))
+	for i, p := range rf.players {
+		d := dirs[rng.Intn(len(dirs))]
+		verb := "MOVE"
+		if rng.Intn(4) == 0 {
+			verb = "BOMB"
+		}
+		to := Pos{p.Pos.X + d.X, p.Pos.Y + d.Y}
+		if !inRange2D(to.X, to.Y, rf.rules.Width, rf.rules.Height) {
+			to = p.Pos
+		}
+		cmds[i] = fmt.Sprintf("%s %d %d", verb, to.X, to.Y)
+	}
+	return cmds
+}
+
+// testStates 는 input.txt 와 무작위 게임 몇 판의 상태들
+func testStates(t testing.TB) []*State {
+	states := inputStates(t)
+	for seed := int64(1); seed <= 4; seed++ {
+		states = append(states, playedStates(seed, 2+int(seed%2)*2, 80)...)
+	}
+	return states
+}
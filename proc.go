@@ -0,0 +1,149 @@
+//go:build !arena
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"time"
+)
+
+// 외부 봇이 잘못됐을 때. 공식 심판처럼 그 턴에 탈락시킨다.
+var (
+	errBotTimeout = errors.New("timeout")
+	errBotCrash   = errors.New("crashed")
+)
+
+// limits 는 외부 봇의 턴별 제한 시간
+type limits struct {
+	first time.Duration
+	turn  time.Duration
+}
+
+var officialLimits = limits{firstTurnTime, turnTime}
+
+func (l *limits) addFlags(fs *flag.FlagSet) {
+	fs.DurationVar(&l.first, "first", l.first, "time limit for the first turn")
+	fs.DurationVar(&l.turn, "turn", l.turn, "time limit for the other turns")
+}
+
+// procBot 은 프로토콜을 쓰는 외부 프로세스. 첫 턴에 init 줄을 같이 보낸다.
+// 출력은 고루틴이 줄 단위로 읽어서 lines 로 넘긴다.
+// 한 턴에 여러 줄을 쓰면 남는 줄은 다음 턴의 출력이 된다. (공식 심판과 같다)
+type procBot struct {
+	cmd    *exec.Cmd
+	in     io.WriteCloser
+	lines  chan string
+	limits limits
+	turns  int
+	stderr *tailBuffer
+	err    error // 한번 실패하면 계속 그 에러
+}
+
+func startBot(command string, l limits) (*procBot, error) {
+	args := strings.Fields(command)
+	if len(args) == 0 {
+		return nil, errors.New("empty bot command")
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	in, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	out, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	b := &procBot{cmd: cmd, in: in, lines: make(chan string, 16), limits: l, stderr: &tailBuffer{max: 2048}}
+	cmd.Stderr = b.stderr
+	if err := cmd.Start(); err != nil {
+		return nil, err
+	}
+	go func() {
+		sc := bufio.NewScanner(out)
+		for sc.Scan() {
+			b.lines <- sc.Text()
+		}
+		close(b.lines)
+	}()
+	return b, nil
+}
+
+func (b *procBot) turn(s *State) (string, error) {
+	if b.err != nil {
+		return "", b.err
+	}
+	b.turns++
+	limit := b.limits.turn
+	if b.turns == 1 {
+		limit = b.limits.first
+	}
+	// 시간은 입력을 다 쓴 때부터 잰다.
+	w := bufio.NewWriter(b.in)
+	if b.turns == 1 {
+		s.WriteInit(w)
+	}
+	s.WriteProtocol(w)
+	if err := w.Flush(); err != nil {
+		return "", b.fail(errBotCrash, err)
+	}
+
+	timer := time.NewTimer(limit)
+	defer timer.Stop()
+	select {
+	case line, ok := <-b.lines:
+		if !ok {
+			return "", b.fail(errBotCrash, io.ErrUnexpectedEOF)
+		}
+		return strings.TrimSpace(line), nil
+	case <-timer.C:
+		return "", b.fail(errBotTimeout, fmt.Errorf("no output in %v", limit))
+	}
+}
+
+// fail 은 봇을 탈락시키고 이유를 남긴다.
+func (b *procBot) fail(kind, err error) error {
+	b.err = fmt.Errorf("%w: turn %d: %v", kind, b.turns, err)
+	if tail := b.stderr.String(); tail != "" {
+		b.err = fmt.Errorf("%w\n%s", b.err, tail)
+	}
+	return b.err
+}
+
+func (b *procBot) close() error {
+	b.in.Close()
+	b.cmd.Process.Kill()
+	err := b.cmd.Wait()
+	for range b.lines {
+	}
+	return err
+}
+
+// tailBuffer 는 stderr 의 마지막 max 바이트만 남긴다.
+type tailBuffer struct {
+	mu  sync.Mutex
+	max int
+	buf []byte
+}
+
+func (t *tailBuffer) Write(p []byte) (int, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.buf = append(t.buf, p...)
+	if over := len(t.buf) - t.max; over > 0 {
+		t.buf = t.buf[over:]
+	}
+	return len(p), nil
+}
+
+func (t *tailBuffer) String() string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return strings.TrimSpace(string(t.buf))
+}
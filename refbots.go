@@ -0,0 +1,169 @@
+package main
+
+import "math/rand"
+
+// 대전 상대로 쓰는 참고용 봇들. 모두 think 보다 단순하고, 지금 있는 폭탄만 피한다.
+
+// safeStep 은 to 까지 가는 안전한 경로의 첫 칸. 못 가면 제자리
+func safeStep(to Pos3) Pos {
+	origin := me.Pos.at(0)
+	return firstStep(origin.pathTo(to, bombs), origin).Pos()
+}
+
+// stay 는 지금 있는 폭탄들만 피해서 움직인다.
+func stay(why string) action {
+	origin := me.Pos.at(0)
+	path, _ := me.canEscapeFrom(origin, bombs)
+	a := action{pos: firstStep(path, origin).Pos()}
+	trace.choose(a, why)
+	return a
+}
+
+// exploding 은 p 에서 p.Z 에 터지는 폭탄이 있는지.
+// bfs 는 시작 칸의 폭발은 보지 않기 때문에 한 칸 앞을 시작점으로 쓸 때는 따로 봐야 한다.
+func exploding(p Pos3) bool {
+	for _, b := range bombs {
+		if b.CountDown-1 == p.Z && rules.Kills && b.inRange(p.Pos()) {
+			return true
+		}
+	}
+	return false
+}
+
+// randomBot 은 안전한 칸 중에서 아무데나 가고, 가끔 안전하면 폭탄을 놓는다.
+type randomBot struct {
+	rng *rand.Rand
+}
+
+func (b *randomBot) act(s *State) action {
+	origin := me.Pos.at(0)
+	if me.Bombs > 0 && b.rng.Intn(4) == 0 {
+		if surviveIfAllBombs(origin, true, bombs) {
+			a := action{true, me.Pos}
+			trace.choose(a, "random bomb")
+			return a
+		}
+	}
+	// 상대가 폭탄을 놓아도 살 수 있는 칸이 있으면 그 중에서 고른다.
+	var safe, safer []Pos
+	for _, step := range []Pos{{0, 0}, {0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+		p := Pos3{me.Pos.X + step.X, me.Pos.Y + step.Y, 1}
+		if !(step == Pos{}) && !canGo(p, bombs) || exploding(p) {
+			continue
+		}
+		if _, ok := me.canEscapeFrom(p, bombs); ok {
+			safe = append(safe, p.Pos())
+			if surviveIfAllBombs(p, false, bombs) {
+				safer = append(safer, p.Pos())
+			}
+		}
+	}
+	if len(safer) > 0 {
+		safe = safer
+	}
+	if len(safe) == 0 {
+		return stay("random: nowhere safe")
+	}
+	a := action{pos: safe[b.rng.Intn(len(safe))]}
+	trace.choose(a, "random move")
+	return a
+}
+
+// farmerBot 은 가장 가까운, 상자를 하나라도 부술 수 있는 곳에 폭탄을 놓는다.
+type farmerBot struct{}
+
+func (farmerBot) act(s *State) action {
+	origin := me.Pos.at(0)
+	var target Pos3
+	found := false
+	bfs(origin, bombs, items, func(x, y, d, x0, y0 int, bs []Bomb, is []Item) bool {
+		pos := Pos3{x, y, d}
+		if ok, _, n := me.canDropBomb(pos, bs); ok {
+			trace.consider("bomb", pos, float64(n), "nearest")
+			target, found = pos, true
+			return true
+		}
+		return false
+	})
+	if !found {
+		return stay("farmer: nothing to bomb")
+	}
+	if target == origin {
+		_, safe, _ := me.canDropBomb(origin, bombs)
+		a := action{true, safe.Pos()}
+		trace.choose(a, "farmer: bomb")
+		return a
+	}
+	a := action{pos: safeStep(target)}
+	trace.choose(a, "farmer: go to bomb spot")
+	return a
+}
+
+// hoarderBot 은 갈 수 있는 아이템이 있으면 거리와 상관없이 주우러 가고, 없으면 farmer 처럼 한다.
+type hoarderBot struct{}
+
+func (hoarderBot) act(s *State) action {
+	origin := me.Pos.at(0)
+	var target Pos3
+	found := false
+	bfs(origin, bombs, items, func(x, y, d, x0, y0 int, bs []Bomb, is []Item) bool {
+		for _, it := range is {
+			if it.Pos == (Pos{x, y}) {
+				target, found = Pos3{x, y, d}, true
+				trace.consider("item", target, float64(d), "")
+				return true
+			}
+		}
+		return false
+	})
+	if !found {
+		return farmerBot{}.act(s)
+	}
+	a := action{pos: safeStep(target)}
+	trace.choose(a, "hoarder: item")
+	return a
+}
+
+// hunterBot 은 상대가 폭발 범위에 있으면 폭탄을 놓고, 아니면 가장 가까운 상대에게 다가간다.
+// 다가갈 상대가 없으면 farmer 처럼 한다.
+type hunterBot struct{}
+
+func (hunterBot) act(s *State) action {
+	origin := me.Pos.at(0)
+	if me.Bombs > 0 {
+		b := Bomb{Pos: me.Pos, Owner: myID, Range: me.Range}
+		for _, p := range players {
+			if p.ID != myID && b.inRange(p.Pos) {
+				if surviveIfAllBombs(origin, true, bombs) {
+					a := action{true, me.Pos}
+					trace.choose(a, "hunter: enemy in range")
+					return a
+				}
+			}
+		}
+	}
+
+	var target Pos3
+	found := false
+	bfs(origin, bombs, items, func(x, y, d, x0, y0 int, bs []Bomb, is []Item) bool {
+		here := Pos{x, y}
+		for _, p := range players {
+			if p.ID != myID && here.adjacent(p.Pos) {
+				target, found = Pos3{x, y, d}, true
+				trace.consider("hunt", target, float64(d), "")
+				return true
+			}
+		}
+		return false
+	})
+	if !found {
+		return farmerBot{}.act(s)
+	}
+	step := safeStep(target)
+	if !surviveIfAllBombs(step.at(1), false, bombs) {
+		return farmerBot{}.act(s)
+	}
+	a := action{pos: step}
+	trace.choose(a, "hunter: chase")
+	return a
+}
@@ -0,0 +1,388 @@
+//go:build !arena
+
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// 죽은 이유
+const (
+	deathOwnBomb   = "own bomb"
+	deathEnemyBomb = "enemy bomb"
+	deathTimeout   = "timeout"
+	deathCrash     = "crash"
+	deathBadOutput = "bad output"
+)
+
+// refPlayer 는 심판이 보는 플레이어
+type refPlayer struct {
+	Player
+	Alive     bool
+	Boxes     int // 부순 상자 수
+	Death     string
+	DeathTurn int
+}
+
+// referee 는 로컬 대전용 심판. 전역변수를 쓰지 않아서 여러 판을 동시에 돌릴 수 있다.
+//
+// 한 턴은 이 순서로 진행한다.
+//  1. 폭탄 카운트다운을 줄이고, 0 이 된 폭탄을 연쇄폭발까지 터뜨린다.
+//     불길에 닿은 상자/아이템이 없어지고 플레이어가 죽는다. 상자에서는 아이템이 나온다.
+//  2. 살아있는 플레이어들이 BOMB 이면 제자리에 폭탄을 놓는다.
+//  3. 플레이어들이 목표 쪽으로 한 칸 움직이고, 도착한 칸의 아이템을 줍는다.
+type referee struct {
+	rules   Rules
+	turn    int
+	cells   [][]byte
+	players []*refPlayer
+	bombs   []Bomb
+	items   []Item
+	// cleared 는 상자가 모두 없어진 턴 (0 이면 아직 남아있다)
+	cleared int
+}
+
+// endDelay 는 상자가 모두 없어지고 나서 몇 턴 더 하는지
+const endDelay = 20
+
+func newReferee(g generated, r Rules) *referee {
+	rf := &referee{rules: r}
+	for _, row := range g.State.Board {
+		rf.cells = append(rf.cells, []byte(row))
+	}
+	for _, p := range g.State.Players() {
+		rf.players = append(rf.players, &refPlayer{Player: p, Alive: true})
+	}
+	return rf
+}
+
+// state 는 id 플레이어에게 줄 이번 턴 입력
+func (rf *referee) state(id int) *State {
+	s := &State{Width: rf.rules.Width, Height: rf.rules.Height, MyID: id}
+	for _, row := range rf.cells {
+		s.Board = append(s.Board, string(row))
+	}
+	for _, p := range rf.players {
+		if p.Alive {
+			s.Entities = append(s.Entities, Entity{EntityPlayer, p.ID, p.Pos.X, p.Pos.Y, p.Bombs, p.Range})
+		}
+	}
+	for _, b := range rf.bombs {
+		s.Entities = append(s.Entities, Entity{EntityBomb, b.Owner, b.Pos.X, b.Pos.Y, b.CountDown, b.Range})
+	}
+	for _, i := range rf.items {
+		s.Entities = append(s.Entities, Entity{EntityItem, 0, i.Pos.X, i.Pos.Y, i.Type, 0})
+	}
+	return s
+}
+
+func (rf *referee) alive() int {
+	n := 0
+	for _, p := range rf.players {
+		if p.Alive {
+			n++
+		}
+	}
+	return n
+}
+
+// over 는 게임이 끝났는지
+func (rf *referee) over() bool {
+	if rf.alive() <= 1 || rf.turn >= rf.rules.MaxTurns {
+		return true
+	}
+	return rf.cleared > 0 && rf.turn-rf.cleared >= endDelay
+}
+
+// kill 은 플레이어를 turn 턴에 죽은 것으로 한다.
+func (rf *referee) kill(p *refPlayer, cause string, turn int) {
+	if !p.Alive {
+		return
+	}
+	p.Alive = false
+	p.Death = cause
+	p.DeathTurn = turn
+}
+
+// forfeit 은 다음 턴 출력을 내지 못한 플레이어를 그 턴에 죽은 것으로 한다.
+func (rf *referee) forfeit(id int, cause string) {
+	rf.kill(rf.players[id], cause, rf.turn+1)
+}
+
+// command 는 "MOVE x y [message]" 나 "BOMB x y [message]" 를 읽는다.
+func (rf *referee) command(line string) (action, error) {
+	f := strings.Fields(line)
+	if len(f) < 3 || (f[0] != "MOVE" && f[0] != "BOMB") {
+		return action{}, fmt.Errorf("%w: %q", ErrSyntax, line)
+	}
+	x, err1 := strconv.Atoi(f[1])
+	y, err2 := strconv.Atoi(f[2])
+	if err1 != nil || err2 != nil {
+		return action{}, fmt.Errorf("%w: %q", ErrSyntax, line)
+	}
+	if !inRange2D(x, y, rf.rules.Width, rf.rules.Height) {
+		return action{}, fmt.Errorf("%w: %q", ErrRange, line)
+	}
+	return action{f[0] == "BOMB", Pos{x, y}}, nil
+}
+
+// step 은 한 턴을 진행한다. cmds[i] 는 i 번 플레이어의 출력이고, 죽은 플레이어 것은 무시한다.
+func (rf *referee) step(cmds []string) {
+	rf.turn++
+	rf.explode()
+
+	acts := make([]action, len(rf.players))
+	for i, p := range rf.players {
+		if !p.Alive {
+			continue
+		}
+		a, err := rf.command(cmds[i])
+		if err != nil {
+			lg.info(tagReferee, "bad output", "id", p.ID, "turn", rf.turn, "err", err)
+			rf.kill(p, deathBadOutput, rf.turn)
+			continue
+		}
+		acts[i] = a
+	}
+
+	for i, p := range rf.players {
+		if p.Alive && acts[i].bomb && p.Bombs > 0 && rf.bombAt(p.Pos) < 0 {
+			rf.bombs = append(rf.bombs, Bomb{Pos: p.Pos, Owner: p.ID, CountDown: rf.rules.BombTimer, Range: p.Range})
+			p.Bombs--
+		}
+	}
+
+	var dest []Pos
+	for i, p := range rf.players {
+		d := p.Pos
+		if p.Alive {
+			d = rf.moveToward(p.Pos, acts[i].pos)
+		}
+		dest = append(dest, d)
+	}
+	for i, p := range rf.players {
+		p.Pos = dest[i]
+	}
+
+	// 같은 칸에 여럿이 오면 모두 줍는다.
+	picked := map[Pos]bool{}
+	for _, p := range rf.players {
+		if !p.Alive {
+			continue
+		}
+		for _, it := range rf.items {
+			if it.Pos == p.Pos {
+				rf.rules.pickup(&p.Player, it.Type)
+				picked[it.Pos] = true
+			}
+		}
+	}
+	rf.items = filterItems(rf.items, func(it Item) bool { return !picked[it.Pos] })
+
+	if rf.cleared == 0 && rf.boxes() == 0 {
+		rf.cleared = rf.turn
+	}
+}
+
+func (rf *referee) boxes() int {
+	n := 0
+	for _, row := range rf.cells {
+		for _, c := range row {
+			if c == cellBoxEmpty || c == cellBoxRange || c == cellBoxPlus {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+func (rf *referee) bombAt(p Pos) int {
+	for i, b := range rf.bombs {
+		if b.Pos == p {
+			return i
+		}
+	}
+	return -1
+}
+
+func (rf *referee) itemAt(p Pos) bool {
+	for _, it := range rf.items {
+		if it.Pos == p {
+			return true
+		}
+	}
+	return false
+}
+
+// blast 는 폭탄 하나의 불길. 벽에서 멈추고, 상자/아이템/폭탄은 그 칸까지 태우고 멈춘다.
+func (rf *referee) blast(b Bomb) []Pos {
+	cells := []Pos{b.Pos}
+	for _, dir := range []Pos{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+		for i := 1; i < b.Range; i++ {
+			p := Pos{b.Pos.X + dir.X*i, b.Pos.Y + dir.Y*i}
+			if !inRange2D(p.X, p.Y, rf.rules.Width, rf.rules.Height) || rf.cells[p.Y][p.X] == cellWall {
+				break
+			}
+			cells = append(cells, p)
+			if rf.cells[p.Y][p.X] != cellFloor || rf.itemAt(p) || rf.bombAt(p) >= 0 {
+				break
+			}
+		}
+	}
+	return cells
+}
+
+// explode 는 카운트다운이 끝난 폭탄들을 연쇄폭발까지 한꺼번에 터뜨린다.
+// 상자 하나를 여러 폭탄이 같이 부수면 폭탄 주인마다 하나씩 센다.
+func (rf *referee) explode() {
+	for i := range rf.bombs {
+		rf.bombs[i].CountDown--
+	}
+	// fire 는 불길이 닿은 칸과 그 칸을 태운 폭탄 주인들
+	fire := map[Pos]map[int]bool{}
+	done := make([]bool, len(rf.bombs))
+	for {
+		found := false
+		for i, b := range rf.bombs {
+			if done[i] || (b.CountDown > 0 && fire[b.Pos] == nil) {
+				continue
+			}
+			done[i] = true
+			found = true
+			for _, p := range rf.blast(b) {
+				if fire[p] == nil {
+					fire[p] = map[int]bool{}
+				}
+				fire[p][b.Owner] = true
+			}
+		}
+		if !found {
+			break
+		}
+	}
+	if len(fire) == 0 {
+		return
+	}
+
+	var remaining []Bomb
+	for i, b := range rf.bombs {
+		if !done[i] {
+			remaining = append(remaining, b)
+			continue
+		}
+		for _, p := range rf.players {
+			if p.ID == b.Owner {
+				p.Bombs++
+			}
+		}
+	}
+	rf.bombs = remaining
+
+	rf.items = filterItems(rf.items, func(it Item) bool { return fire[it.Pos] == nil })
+	for p, owners := range fire {
+		c := rf.cells[p.Y][p.X]
+		if c != cellBoxEmpty && c != cellBoxRange && c != cellBoxPlus {
+			continue
+		}
+		rf.cells[p.Y][p.X] = cellFloor
+		for _, pl := range rf.players {
+			if owners[pl.ID] {
+				pl.Boxes++
+			}
+		}
+		if rf.rules.Items && c != cellBoxEmpty {
+			rf.items = append(rf.items, Item{p, int(c - cellBoxEmpty)})
+		}
+	}
+
+	for _, pl := range rf.players {
+		owners := fire[pl.Pos]
+		if !pl.Alive || owners == nil || !rf.rules.Kills {
+			continue
+		}
+		cause := deathEnemyBomb
+		if owners[pl.ID] {
+			cause = deathOwnBomb
+		}
+		rf.kill(pl, cause, rf.turn)
+	}
+}
+
+// moveToward 는 to 로 가는 최단 경로의 첫 칸. 갈 수 없으면 to 에 가장 가까운 곳으로 간다.
+func (rf *referee) moveToward(from, to Pos) Pos {
+	type node struct {
+		pos   Pos
+		first Pos
+		d     int
+	}
+	best := node{from, from, 0}
+	seen := map[Pos]bool{from: true}
+	queue := []node{best}
+	for len(queue) > 0 {
+		n := queue[0]
+		queue = queue[1:]
+		if manhattan(n.pos, to) < manhattan(best.pos, to) {
+			best = n
+		}
+		for _, dir := range []Pos{{0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+			p := Pos{n.pos.X + dir.X, n.pos.Y + dir.Y}
+			if seen[p] || !inRange2D(p.X, p.Y, rf.rules.Width, rf.rules.Height) ||
+				rf.cells[p.Y][p.X] != cellFloor || rf.bombAt(p) >= 0 {
+				continue
+			}
+			seen[p] = true
+			first := n.first
+			if n.d == 0 {
+				first = p
+			}
+			queue = append(queue, node{p, first, n.d + 1})
+		}
+	}
+	return best.first
+}
+
+func filterItems(items []Item, keep func(Item) bool) []Item {
+	var result []Item
+	for _, it := range items {
+		if keep(it) {
+			result = append(result, it)
+		}
+	}
+	return result
+}
+
+// ranks 는 플레이어별 순위 (1 이 1등, 같으면 같은 순위).
+// 살아남은 쪽이 위고, 늦게 죽은 쪽이 위고, 그 다음은 부순 상자 수로 정한다.
+func (rf *referee) ranks() []int {
+	better := func(a, b *refPlayer) int {
+		key := func(p *refPlayer) [3]int {
+			alive := 0
+			if p.Alive {
+				alive = 1
+			}
+			return [3]int{alive, p.DeathTurn, p.Boxes}
+		}
+		ka, kb := key(a), key(b)
+		for i := range ka {
+			if ka[i] != kb[i] {
+				if ka[i] > kb[i] {
+					return 1
+				}
+				return -1
+			}
+		}
+		return 0
+	}
+	ranks := make([]int, len(rf.players))
+	for i, p := range rf.players {
+		ranks[i] = 1
+		for _, o := range rf.players {
+			if better(o, p) > 0 {
+				ranks[i]++
+			}
+		}
+	}
+	return ranks
+}
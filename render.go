@@ -0,0 +1,177 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// renderer 는 보드를 한 칸에 두 글자씩 그린다.
+//
+//	##  벽          []  빈 상자      [r [b  아이템 상자(range, bomb)
+//	+r +b 아이템    P0  플레이어 0   p0     폭탄 위의 플레이어 0
+//	@8  폭탄(남은 턴)
+//
+// 겹쳐 그리는 것들 (빈 칸 위에만)
+//
+//	*3  3턴 뒤에 불길이 지나감    :2  고른 경로의 2턴째    <>  목적지/피할 곳
+type renderer struct {
+	color   bool
+	blast   bool
+	path    []Pos3
+	targets []Pos
+}
+
+// plainRenderer 는 아레나 stderr 용 (색 없음)
+var plainRenderer = renderer{blast: true}
+
+const (
+	ansiReset = "\x1b[0m"
+	ansiWall  = "\x1b[90m"
+	ansiBox   = "\x1b[33m"
+	ansiItem  = "\x1b[32m"
+	ansiBomb  = "\x1b[1;31m"
+	ansiFire  = "\x1b[41m"
+	ansiHeat  = "\x1b[31m"
+	ansiPath  = "\x1b[44m"
+	ansiGoal  = "\x1b[42m"
+)
+
+var ansiPlayers = [maxPlayers]string{"\x1b[1;36m", "\x1b[1;35m", "\x1b[1;34m", "\x1b[1;37m"}
+
+type cell struct {
+	text  string
+	color string
+}
+
+// withTrace 는 결정 기록의 경로와 목적지를 겹쳐 그린다.
+func (r renderer) withTrace(d *decision) renderer {
+	r.path = d.Path
+	r.targets = []Pos{d.Target.Pos()}
+	for _, c := range d.Candidates {
+		if c.Kind == "escape" {
+			r.targets = append(r.targets, c.Pos.Pos())
+		}
+	}
+	return r
+}
+
+// renderTrace 는 지금 상태(current)를 결정 기록과 함께 그린다.
+func (r renderer) renderTrace(d *decision) []string {
+	if current == nil {
+		return nil
+	}
+	return r.withTrace(d).render(current)
+}
+
+func (r renderer) render(s *State) []string {
+	cells := make([][]cell, s.Height)
+	for y, row := range s.Board {
+		cells[y] = make([]cell, s.Width)
+		for x := 0; x < s.Width; x++ {
+			switch row[x] {
+			case cellWall:
+				cells[y][x] = cell{"##", ansiWall}
+			case cellBoxEmpty:
+				cells[y][x] = cell{"[]", ansiBox}
+			case cellBoxRange:
+				cells[y][x] = cell{"[r", ansiBox}
+			case cellBoxPlus:
+				cells[y][x] = cell{"[b", ansiBox}
+			default:
+				cells[y][x] = cell{". ", ""}
+			}
+		}
+	}
+	floor := func(p Pos) bool {
+		return p.Y >= 0 && p.Y < s.Height && p.X >= 0 && p.X < s.Width && cells[p.Y][p.X].text == ". "
+	}
+
+	if r.blast {
+		if geo.w != s.Width || geo.h != s.Height {
+			setLayout(s.Width, s.Height)
+		}
+		var b [][]int
+		for _, row := range s.Board {
+			line := make([]int, len(row))
+			for x := range row {
+				line[x] = int(row[x])
+			}
+			b = append(b, line)
+		}
+		bs := s.Bombs()
+		bb := newBitBoard(b, bs, s.Items())
+		fire := bb.danger(bs, bbHorizon-1)
+		for d := len(fire) - 1; d > 0; d-- {
+			for f := fire[d]; !f.isZero(); {
+				i := f.lowest()
+				f.unset(i)
+				p := geo.pos(i)
+				if floor(p) || strings.HasPrefix(cells[p.Y][p.X].text, "*") {
+					c := cell{fmt.Sprintf("*%d", d%10), ansiHeat}
+					if d == 1 {
+						c.color = ansiFire
+					}
+					cells[p.Y][p.X] = c
+				}
+			}
+		}
+	}
+
+	for _, p := range r.path {
+		if floor(p.Pos()) {
+			cells[p.Y][p.X] = cell{fmt.Sprintf(":%d", p.Z%10), ansiPath}
+		}
+	}
+	for _, p := range r.targets {
+		if p.Y >= 0 && p.Y < s.Height && p.X >= 0 && p.X < s.Width && s.Board[p.Y][p.X] == cellFloor {
+			cells[p.Y][p.X] = cell{"<>", ansiGoal}
+		}
+	}
+
+	for _, e := range s.Entities {
+		switch e.Type {
+		case EntityItem:
+			c := cell{"+r", ansiItem}
+			if e.Param1 == itemExtraBomb {
+				c.text = "+b"
+			}
+			cells[e.Y][e.X] = c
+		case EntityBomb:
+			cells[e.Y][e.X] = cell{fmt.Sprintf("@%d", e.Param1%10), ansiBomb}
+		}
+	}
+	for _, e := range s.Entities {
+		if e.Type != EntityPlayer {
+			continue
+		}
+		c := cell{fmt.Sprintf("P%d", e.Owner), ansiPlayers[e.Owner%maxPlayers]}
+		if strings.HasPrefix(cells[e.Y][e.X].text, "@") {
+			c.text = fmt.Sprintf("p%d", e.Owner)
+		}
+		cells[e.Y][e.X] = c
+	}
+
+	var lines []string
+	var sb strings.Builder
+	sb.WriteString("   ")
+	for x := 0; x < s.Width; x++ {
+		fmt.Fprintf(&sb, "%-2d", x%10)
+	}
+	lines = append(lines, strings.TrimRight(sb.String(), " "))
+	for y := range cells {
+		sb.Reset()
+		fmt.Fprintf(&sb, "%2d ", y)
+		for _, c := range cells[y] {
+			if r.color && c.color != "" {
+				sb.WriteString(c.color + c.text + ansiReset)
+			} else {
+				sb.WriteString(c.text)
+			}
+		}
+		lines = append(lines, sb.String())
+	}
+	for _, p := range s.Players() {
+		lines = append(lines, fmt.Sprintf("   P%d (%d,%d) bombs=%d range=%d", p.ID, p.Pos.X, p.Pos.Y, p.Bombs, p.Range))
+	}
+	return lines
+}
@@ -0,0 +1,78 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Rules 는 리그나 변형 규칙마다 달라지는 값들.
+// 시뮬레이션과 판단은 상수 대신 모두 여기를 본다.
+type Rules struct {
+	League     string `json:"league"`
+	Items      bool   `json:"items"` // 상자에서 아이템이 나오는지
+	Kills      bool   `json:"kills"` // 폭탄에 플레이어가 죽는지
+	Width      int    `json:"width"`
+	Height     int    `json:"height"`
+	BombTimer  int    `json:"bombTimer"`  // 놓은 폭탄이 터질 때까지의 턴 수
+	StartBombs int    `json:"startBombs"` // 처음 가진 폭탄 수
+	StartRange int    `json:"startRange"` // 처음 폭발 범위 (자기 칸 포함)
+	RangeItem  int    `json:"rangeItem"`  // extra range 아이템 하나로 늘어나는 범위
+	BombItem   int    `json:"bombItem"`   // extra bomb 아이템 하나로 늘어나는 폭탄 수
+	MaxTurns   int    `json:"maxTurns"`
+	// SearchDepth 는 bfs 가 내다보는 턴 수. 0 이면 보드 폭만큼
+	SearchDepth int `json:"searchDepth"`
+}
+
+var defaultRules = Rules{
+	League:     "bronze",
+	Items:      true,
+	Kills:      true,
+	Width:      13,
+	Height:     11,
+	BombTimer:  8,
+	StartBombs: 1,
+	StartRange: 3,
+	RangeItem:  1,
+	BombItem:   1,
+	MaxTurns:   200,
+}
+
+var rules = defaultRules
+
+// searchDepth 는 bfs 가 몇 턴까지 내려갈지
+func (r *Rules) searchDepth() int {
+	if r.SearchDepth > 0 {
+		return r.SearchDepth
+	}
+	return width
+}
+
+// loadRules 는 JSON 파일에서 규칙을 읽는다. 파일에 없는 값은 base 그대로다.
+func loadRules(path string, base Rules) (Rules, error) {
+	r := base
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return r, err
+	}
+	if err := json.Unmarshal(data, &r); err != nil {
+		return r, fmt.Errorf("%s: %w", path, err)
+	}
+	if r.BombTimer < 1 || r.BombTimer+2 >= bbHorizon {
+		return r, fmt.Errorf("%s: bombTimer %d out of range", path, r.BombTimer)
+	}
+	if r.Width < 1 || r.Height < 1 || (r.Height+1)*r.Width > bbBits {
+		return r, fmt.Errorf("%s: board %dx%d too large", path, r.Width, r.Height)
+	}
+	return r, nil
+}
+
+// pickup 은 아이템을 주웠을 때 플레이어가 얻는 것
+func (r *Rules) pickup(p *Player, itemType int) {
+	switch itemType {
+	case itemExtraRange:
+		p.Range += r.RangeItem
+	case itemExtraBomb:
+		p.Bombs += r.BombItem
+	}
+}
@@ -0,0 +1,204 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
+	"encoding/base64"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// 매 턴 입력 전체를 stderr 에 남겨서 아레나 로그에서 다시 꺼낼 수 있게 한다.
+//
+//	#HS turn=3 enc=text
+//	13 11 0
+//	...board, entities...
+//	#HS end
+//
+// enc=gz64 이면 가운데는 gzip 한 걸 base64 로 쓴 줄들이다.
+const (
+	snapshotMark = "#HS "
+	snapshotEnd  = "#HS end"
+)
+
+// snapshot 모드: off, text, gz64
+// 기본값은 빌드마다 다르다 (snapshot_default.go, snapshot_arena.go).
+var snapshotMode = envOr("HS_SNAPSHOT", snapshotDefault)
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+func writeSnapshot(w io.Writer, turn int, s *State) {
+	if snapshotMode == "off" {
+		return
+	}
+	var body bytes.Buffer
+	s.WriteInit(&body)
+	s.WriteProtocol(&body)
+
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+	switch snapshotMode {
+	case "gz64":
+		var z bytes.Buffer
+		zw, _ := gzip.NewWriterLevel(&z, gzip.BestCompression)
+		zw.Write(body.Bytes())
+		zw.Close()
+		enc := base64.StdEncoding.EncodeToString(z.Bytes())
+		fmt.Fprintf(bw, "%sturn=%d enc=gz64\n", snapshotMark, turn)
+		for len(enc) > 0 {
+			n := min(len(enc), 64)
+			fmt.Fprintln(bw, enc[:n])
+			enc = enc[n:]
+		}
+	default:
+		fmt.Fprintf(bw, "%sturn=%d enc=text\n", snapshotMark, turn)
+		bw.Write(body.Bytes())
+	}
+	fmt.Fprintln(bw, snapshotEnd)
+}
+
+// snapshotBlock 은 로그에서 꺼낸 스냅샷 하나
+type snapshotBlock struct {
+	turn  int
+	enc   string
+	lines []string
+}
+
+func (b *snapshotBlock) decode() (*State, error) {
+	var body io.Reader
+	switch b.enc {
+	case "text":
+		body = strings.NewReader(strings.Join(b.lines, "\n"))
+	case "gz64":
+		data, err := base64.StdEncoding.DecodeString(strings.Join(b.lines, ""))
+		if err != nil {
+			return nil, err
+		}
+		zr, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return nil, err
+		}
+		body = zr
+	default:
+		return nil, fmt.Errorf("unknown encoding %q", b.enc)
+	}
+	p := newParser(body)
+	if err := p.readInit(); err != nil {
+		return nil, err
+	}
+	return p.readTurn()
+}
+
+// scanSnapshots 는 붙여넣은 로그에서 스냅샷 블록들을 찾는다.
+// CodinGame 로그는 줄 앞에 다른 글자가 붙어 있을 수 있어서
+// 시작 표시가 나온 자리의 들여쓰기만큼 블록 안의 줄들에서 떼어낸다.
+func scanSnapshots(r io.Reader) ([]snapshotBlock, error) {
+	var blocks []snapshotBlock
+	var cur *snapshotBlock
+	prefix := 0
+	sc := bufio.NewScanner(r)
+	sc.Buffer(make([]byte, 64*1024), 1024*1024)
+	for sc.Scan() {
+		line := strings.TrimRight(sc.Text(), " \t\r")
+		if i := strings.Index(line, snapshotMark); i >= 0 && !strings.HasPrefix(line[i:], snapshotEnd) {
+			cur = &snapshotBlock{turn: -1}
+			prefix = i
+			for _, f := range strings.Fields(line[i+len(snapshotMark):]) {
+				k, v, _ := strings.Cut(f, "=")
+				switch k {
+				case "turn":
+					cur.turn, _ = strconv.Atoi(v)
+				case "enc":
+					cur.enc = v
+				}
+			}
+			continue
+		}
+		if cur == nil {
+			continue
+		}
+		if strings.Contains(line, snapshotEnd) {
+			blocks = append(blocks, *cur)
+			cur = nil
+			continue
+		}
+		if len(line) >= prefix {
+			line = line[prefix:]
+		}
+		cur.lines = append(cur.lines, strings.TrimSpace(line))
+	}
+	return blocks, sc.Err()
+}
+
+// extractCommand 는 붙여넣은 아레나 로그에서 스냅샷을 꺼내 트랜스크립트로 만든다.
+//
+//	hypersonic extract [-o transcript.txt] [log.txt]
+func extractCommand(args []string) {
+	fs := flag.NewFlagSet("extract", flag.ExitOnError)
+	out := fs.String("o", "", "write the transcript to `file` instead of stdout")
+	fs.Parse(args)
+
+	var r io.Reader = os.Stdin
+	if fs.NArg() > 0 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	blocks, err := scanSnapshots(r)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// 같은 턴이 여러번 붙여넣어졌으면 처음 것만 쓴다.
+	sort.SliceStable(blocks, func(i, j int) bool { return blocks[i].turn < blocks[j].turn })
+	var states []*State
+	seen := map[int]bool{}
+	for _, b := range blocks {
+		if b.turn >= 0 && seen[b.turn] {
+			continue
+		}
+		seen[b.turn] = true
+		s, err := b.decode()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "extract: turn %d: %v\n", b.turn, err)
+			continue
+		}
+		states = append(states, s)
+	}
+	if len(states) == 0 {
+		fmt.Fprintln(os.Stderr, "extract: no snapshots found")
+		os.Exit(1)
+	}
+
+	var w io.Writer = os.Stdout
+	if *out != "" {
+		f, err := os.Create(*out)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		w = f
+	}
+	bw := bufio.NewWriter(w)
+	defer bw.Flush()
+	writeTranscript(bw, states)
+	fmt.Fprintf(os.Stderr, "extract: %d turns\n", len(states))
+}
@@ -0,0 +1,7 @@
+//go:build arena
+
+package main
+
+// 아레나에서는 환경변수를 줄 수 없으니 처음부터 켠다.
+// gz64 는 턴마다 몇 줄이라 stderr 한도 안에 든다.
+const snapshotDefault = "gz64"
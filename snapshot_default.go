@@ -0,0 +1,6 @@
+//go:build !arena
+
+package main
+
+// 로컬에서는 로그가 조용하도록 끈다. 필요하면 HS_SNAPSHOT 으로 켠다.
+const snapshotDefault = "off"
@@ -0,0 +1,211 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+)
+
+// State 는 한 턴에 읽은 입력 그대로의 상태
+type State struct {
+	Width    int
+	Height   int
+	MyID     int
+	Board    []string
+	Entities []Entity
+}
+
+// Entity 는 입력의 entity 한 줄
+type Entity struct {
+	Type   int
+	Owner  int
+	X      int
+	Y      int
+	Param1 int
+	Param2 int
+}
+
+// Players ...
+func (s *State) Players() []Player {
+	var result []Player
+	for _, e := range s.Entities {
+		if e.Type == EntityPlayer {
+			result = append(result, Player{Pos: Pos{e.X, e.Y}, ID: e.Owner, Bombs: e.Param1, Range: e.Param2})
+		}
+	}
+	return result
+}
+
+// Bombs ...
+func (s *State) Bombs() []Bomb {
+	var result []Bomb
+	for _, e := range s.Entities {
+		if e.Type == EntityBomb {
+			result = append(result, Bomb{Pos: Pos{e.X, e.Y}, Owner: e.Owner, CountDown: e.Param1, Range: e.Param2})
+		}
+	}
+	return result
+}
+
+// Items ...
+func (s *State) Items() []Item {
+	var result []Item
+	for _, e := range s.Entities {
+		if e.Type == EntityItem {
+			result = append(result, Item{Pos: Pos{e.X, e.Y}, Type: e.Param1})
+		}
+	}
+	return result
+}
+
+// current 는 마지막으로 apply 한 상태
+var current *State
+
+// apply 는 상태를 전역변수(board, players, bombs, items, me)로 옮긴다.
+func (s *State) apply() {
+	current = s
+	if width != s.Width || height != s.Height {
+		setLayout(s.Width, s.Height)
+	}
+	width, height, myID = s.Width, s.Height, s.MyID
+	rules.Width, rules.Height = width, height
+
+	board = s.grid()
+
+	players = s.Players()
+	bombs = s.Bombs()
+	items = s.Items()
+	for _, p := range players {
+		if p.ID == myID {
+			me = p
+		}
+	}
+
+	// bombs sync
+	syncBombs(bombs, board, items)
+}
+
+// grid 는 보드를 board 와 같은 [][]int 로 바꾼다.
+func (s *State) grid() [][]int {
+	g := make([][]int, s.Height)
+	for y, row := range s.Board {
+		g[y] = make([]int, s.Width)
+		for x := 0; x < s.Width; x++ {
+			g[y][x] = int(row[x])
+		}
+	}
+	return g
+}
+
+// WriteInit 은 game.init 이 읽는 첫 줄을 쓴다.
+func (s *State) WriteInit(w io.Writer) error {
+	_, err := fmt.Fprintf(w, "%d %d %d\n", s.Width, s.Height, s.MyID)
+	return err
+}
+
+// WriteProtocol 은 game.round 가 읽는 한 턴의 입력을 그대로 쓴다.
+func (s *State) WriteProtocol(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	for _, row := range s.Board {
+		fmt.Fprintln(bw, row)
+	}
+	fmt.Fprintln(bw, len(s.Entities))
+	for _, e := range s.Entities {
+		fmt.Fprintf(bw, "%d %d %d %d %d %d\n", e.Type, e.Owner, e.X, e.Y, e.Param1, e.Param2)
+	}
+	return bw.Flush()
+}
+
+// Validate 는 프로토콜로 써서 다시 읽어보는 것으로 값을 검사한다.
+func (s *State) Validate() error {
+	var buf bytes.Buffer
+	s.WriteInit(&buf)
+	s.WriteProtocol(&buf)
+	p := newParser(&buf)
+	if err := p.readInit(); err != nil {
+		return err
+	}
+	_, err := p.readTurn()
+	return err
+}
+
+type stateJSON struct {
+	Width   int          `json:"width"`
+	Height  int          `json:"height"`
+	MyID    int          `json:"myId"`
+	Board   []string     `json:"board"`
+	Players []playerJSON `json:"players"`
+	Bombs   []bombJSON   `json:"bombs"`
+	Items   []itemJSON   `json:"items"`
+}
+
+type playerJSON struct {
+	ID    int `json:"id"`
+	X     int `json:"x"`
+	Y     int `json:"y"`
+	Bombs int `json:"bombs"`
+	Range int `json:"range"`
+}
+
+type bombJSON struct {
+	Owner     int `json:"owner"`
+	X         int `json:"x"`
+	Y         int `json:"y"`
+	CountDown int `json:"countdown"`
+	Range     int `json:"range"`
+}
+
+type itemJSON struct {
+	X    int `json:"x"`
+	Y    int `json:"y"`
+	Type int `json:"type"`
+}
+
+// MarshalJSON 은 보드와 players, bombs, items 를 나눠서 쓴다.
+func (s *State) MarshalJSON() ([]byte, error) {
+	j := stateJSON{
+		Width:   s.Width,
+		Height:  s.Height,
+		MyID:    s.MyID,
+		Board:   s.Board,
+		Players: []playerJSON{},
+		Bombs:   []bombJSON{},
+		Items:   []itemJSON{},
+	}
+	for _, e := range s.Entities {
+		switch e.Type {
+		case EntityPlayer:
+			j.Players = append(j.Players, playerJSON{e.Owner, e.X, e.Y, e.Param1, e.Param2})
+		case EntityBomb:
+			j.Bombs = append(j.Bombs, bombJSON{e.Owner, e.X, e.Y, e.Param1, e.Param2})
+		case EntityItem:
+			j.Items = append(j.Items, itemJSON{e.X, e.Y, e.Param1})
+		}
+	}
+	return json.Marshal(j)
+}
+
+// UnmarshalJSON 은 entity 를 players, bombs, items 순으로 다시 만든다.
+func (s *State) UnmarshalJSON(data []byte) error {
+	var j stateJSON
+	if err := json.Unmarshal(data, &j); err != nil {
+		return err
+	}
+	t := State{Width: j.Width, Height: j.Height, MyID: j.MyID, Board: j.Board}
+	for _, p := range j.Players {
+		t.Entities = append(t.Entities, Entity{EntityPlayer, p.ID, p.X, p.Y, p.Bombs, p.Range})
+	}
+	for _, b := range j.Bombs {
+		t.Entities = append(t.Entities, Entity{EntityBomb, b.Owner, b.X, b.Y, b.CountDown, b.Range})
+	}
+	for _, i := range j.Items {
+		t.Entities = append(t.Entities, Entity{EntityItem, 0, i.X, i.Y, i.Type, 0})
+	}
+	if err := t.Validate(); err != nil {
+		return err
+	}
+	*s = t
+	return nil
+}
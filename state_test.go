@@ -0,0 +1,59 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+// TestStateRoundTrip 은 프로토콜과 JSON 으로 썼다가 읽어도 상태가 그대로인지 본다.
+func TestStateRoundTrip(t *testing.T) {
+	for i, s := range testStates(t) {
+		if err := roundTrip(s); err != nil {
+			t.Fatalf("state %d: %v", i, err)
+		}
+	}
+}
+
+func TestStateUnmarshalJSON(t *testing.T) {
+	const board = `"board": ["...", ".X.", "..."]`
+	tests := []struct {
+		name string
+		json string
+		err  error
+	}{
+		{"ok", `{"width": 3, "height": 3, "myId": 0, ` + board + `,
+			"players": [{"id": 0, "x": 0, "y": 0, "bombs": 1, "range": 3}],
+			"bombs": [{"owner": 0, "x": 2, "y": 2, "countdown": 8, "range": 3}],
+			"items": [{"x": 0, "y": 2, "type": 2}]}`, nil},
+		{"board", `{"width": 3, "height": 3, "myId": 0, "board": ["...", ".X"]}`, ErrCount},
+		{"player", `{"width": 3, "height": 3, "myId": 0, ` + board + `,
+			"players": [{"id": 0, "x": 3, "y": 0, "bombs": 1, "range": 3}]}`, ErrRange},
+		{"bomb", `{"width": 3, "height": 3, "myId": 0, ` + board + `,
+			"bombs": [{"owner": 0, "x": 2, "y": 2, "countdown": 0, "range": 3}]}`, ErrRange},
+		{"item", `{"width": 3, "height": 3, "myId": 0, ` + board + `,
+			"items": [{"x": 0, "y": 2, "type": 5}]}`, ErrRange},
+		{"myId", `{"width": 3, "height": 3, "myId": 7, ` + board + `}`, ErrRange},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s State
+			err := json.Unmarshal([]byte(tt.json), &s)
+			if tt.err == nil {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if err := roundTrip(&s); err != nil {
+					t.Fatal(err)
+				}
+				if len(s.Players()) != 1 || len(s.Bombs()) != 1 || len(s.Items()) != 1 {
+					t.Errorf("got %d players, %d bombs, %d items", len(s.Players()), len(s.Bombs()), len(s.Items()))
+				}
+				return
+			}
+			if !errors.Is(err, tt.err) {
+				t.Errorf("error %v, want %v", err, tt.err)
+			}
+		})
+	}
+}
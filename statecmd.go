@@ -0,0 +1,185 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+)
+
+// readStates 는 트랜스크립트(프로토콜 텍스트)나 JSON 상태들을 읽는다.
+// 첫 글자가 '{' 이면 JSON 으로 본다.
+func readStates(r io.Reader) ([]*State, error) {
+	br := bufio.NewReader(r)
+	for {
+		c, _, err := br.ReadRune()
+		if err == io.EOF {
+			return nil, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+		if c == ' ' || c == '\t' || c == '\r' || c == '\n' {
+			continue
+		}
+		br.UnreadRune()
+		if c == '{' {
+			return readJSONStates(br)
+		}
+		return readProtocolStates(br)
+	}
+}
+
+func readJSONStates(r io.Reader) ([]*State, error) {
+	var states []*State
+	dec := json.NewDecoder(r)
+	for {
+		s := &State{}
+		err := dec.Decode(s)
+		if err == io.EOF {
+			return states, nil
+		}
+		if err != nil {
+			return states, err
+		}
+		states = append(states, s)
+	}
+}
+
+// readProtocolStates 는 잘못된 턴을 건너뛰며 읽고, 건너뛴 이유를 모아서 돌려준다.
+func readProtocolStates(r io.Reader) ([]*State, error) {
+	var states []*State
+	var errs []error
+	p := newParser(r)
+	if err := p.readInit(); err != nil {
+		return nil, err
+	}
+	for {
+		s, err := p.readTurn()
+		if err == io.EOF {
+			return states, errors.Join(errs...)
+		}
+		if err != nil {
+			errs = append(errs, err)
+			if errors.Is(err, io.ErrUnexpectedEOF) || p.resync() != nil {
+				return states, errors.Join(errs...)
+			}
+			continue
+		}
+		states = append(states, s)
+	}
+}
+
+// writeTranscript 는 상태들을 main 이 읽는 트랜스크립트로 쓴다.
+// 게임 크기나 id 가 바뀌면 init 줄을 다시 쓴다.
+func writeTranscript(w io.Writer, states []*State) error {
+	var last *State
+	for _, s := range states {
+		if last == nil || last.Width != s.Width || last.Height != s.Height || last.MyID != s.MyID {
+			if err := s.WriteInit(w); err != nil {
+				return err
+			}
+		}
+		if err := s.WriteProtocol(w); err != nil {
+			return err
+		}
+		last = s
+	}
+	return nil
+}
+
+// stateCommand 는 상태를 프로토콜 텍스트와 JSON 사이에서 바꾼다.
+//
+//	hypersonic state [-to json|protocol] [file]
+func stateCommand(args []string) {
+	fs := flag.NewFlagSet("state", flag.ExitOnError)
+	to := fs.String("to", "json", "output `format`: json or protocol")
+	check := fs.Bool("check", false, "check that every state round-trips through both formats")
+	fs.Parse(args)
+
+	var r io.Reader = os.Stdin
+	if fs.NArg() > 0 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		r = f
+	}
+
+	states, err := readStates(r)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		if len(states) == 0 {
+			os.Exit(1)
+		}
+	}
+
+	if *check {
+		for i, s := range states {
+			if err := roundTrip(s); err != nil {
+				fmt.Fprintf(os.Stderr, "state %d: %v\n", i, err)
+				os.Exit(1)
+			}
+		}
+		return
+	}
+
+	w := bufio.NewWriter(os.Stdout)
+	defer w.Flush()
+	switch *to {
+	case "json":
+		enc := json.NewEncoder(w)
+		for _, s := range states {
+			enc.Encode(s)
+		}
+	case "protocol":
+		writeTranscript(w, states)
+	default:
+		fmt.Fprintln(os.Stderr, "state: unknown format", *to)
+		os.Exit(2)
+	}
+}
+
+// roundTrip 은 프로토콜 -> 파싱 -> 프로토콜, JSON -> 파싱 -> 프로토콜 이 같은지 본다.
+func roundTrip(s *State) error {
+	var want bytes.Buffer
+	s.WriteInit(&want)
+	s.WriteProtocol(&want)
+
+	parsed, err := readProtocolStates(bytes.NewReader(want.Bytes()))
+	if err != nil {
+		return err
+	}
+	if len(parsed) != 1 {
+		return fmt.Errorf("protocol: got %d states", len(parsed))
+	}
+	var got bytes.Buffer
+	parsed[0].WriteInit(&got)
+	parsed[0].WriteProtocol(&got)
+	if got.String() != want.String() {
+		return fmt.Errorf("protocol round-trip differs:\n%s\nvs\n%s", want.String(), got.String())
+	}
+
+	data, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	var decoded State
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	data2, err := json.Marshal(&decoded)
+	if err != nil {
+		return err
+	}
+	if !bytes.Equal(data, data2) {
+		return fmt.Errorf("json round-trip differs:\n%s\nvs\n%s", data, data2)
+	}
+	return nil
+}
@@ -0,0 +1,25 @@
+package main
+
+import (
+	"os"
+	"testing"
+)
+
+// 테스트들이 같이 쓰는 상태들.
+// input.txt 는 상자가 거의 없어서, 만든 맵에서 무작위로 둔 게임의 상태도 같이 쓴다. (played_test.go)
+// arena 태그로 빌드하면 심판이 없어서 input.txt 의 상태들만 쓴다. (played_arena_test.go)
+
+// inputStates 는 input.txt 의 상태들. input.txt 에는 메모 줄이 섞여 있어서 읽다가 건너뛴 것은 에러로 보지 않는다.
+func inputStates(t testing.TB) []*State {
+	t.Helper()
+	f, err := os.Open("input.txt")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer f.Close()
+	states, _ := readStates(f)
+	if len(states) == 0 {
+		t.Fatal("input.txt: no states")
+	}
+	return states
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// strategy 는 한 턴의 상태를 보고 할 행동을 정한다.
+// act 를 부르기 전에 s 는 apply 되어 있어서 전역변수(board, bombs, me ...)와 bfs 를 그대로 쓸 수 있다.
+// 고른 이유는 trace 에 남긴다.
+type strategy interface {
+	act(s *State) action
+}
+
+// mainBot 은 think 를 쓰는 원래 봇
+type mainBot struct{}
+
+func (mainBot) act(s *State) action {
+	return think()
+}
+
+// bots 는 -bot 플래그로 고를 수 있는 전략들
+var bots = map[string]func(seed int64) strategy{
+	"main":    func(int64) strategy { return mainBot{} },
+	"random":  func(seed int64) strategy { return &randomBot{rand.New(rand.NewSource(seed))} },
+	"farmer":  func(int64) strategy { return farmerBot{} },
+	"hoarder": func(int64) strategy { return hoarderBot{} },
+	"hunter":  func(int64) strategy { return hunterBot{} },
+}
+
+func botNames() string {
+	var names []string
+	for name := range bots {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+func newStrategy(name string, seed int64) (strategy, error) {
+	f, ok := bots[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown bot %q (%s)", name, botNames())
+	}
+	return f(seed), nil
+}
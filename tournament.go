@@ -0,0 +1,369 @@
+//go:build !arena
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"runtime"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"text/tabwriter"
+)
+
+// entrant 는 대회에 나가는 봇 (이름과 실행할 명령)
+type entrant struct {
+	Name    string
+	Command string
+}
+
+// entrants 는 -bot name=command 를 여러번 받는다.
+type entrants []entrant
+
+func (e *entrants) String() string {
+	var parts []string
+	for _, b := range *e {
+		parts = append(parts, b.Name+"="+b.Command)
+	}
+	return strings.Join(parts, ", ")
+}
+
+func (e *entrants) Set(v string) error {
+	name, command, ok := strings.Cut(v, "=")
+	if !ok || name == "" || strings.TrimSpace(command) == "" {
+		return fmt.Errorf("want name=command, got %q", v)
+	}
+	*e = append(*e, entrant{name, command})
+	return nil
+}
+
+// fixture 는 한 판의 대진. Seats[i] 는 i 번 플레이어로 나가는 봇 번호
+type fixture struct {
+	Seed  int64
+	Seats []int
+}
+
+// played 는 대진과 결과
+type played struct {
+	fixture
+	Result matchResult
+}
+
+// schedule 은 seed 로 대진표를 만든다. 판마다 sizes 를 돌아가며 쓰고,
+// 자리마다 봇을 골고루 섞어서 넣는다. (봇이 자리 수보다 적으면 같은 봇이 여럿 나간다.)
+func schedule(seed int64, games int, sizes []int, nBots int) []fixture {
+	rng := rand.New(rand.NewSource(seed))
+	var fixtures []fixture
+	for g := 0; g < games; g++ {
+		n := sizes[g%len(sizes)]
+		var seats []int
+		for len(seats) < n {
+			seats = append(seats, rng.Perm(nBots)...)
+		}
+		seats = seats[:n]
+		rng.Shuffle(n, func(i, j int) { seats[i], seats[j] = seats[j], seats[i] })
+		fixtures = append(fixtures, fixture{seed + int64(g), seats})
+	}
+	return fixtures
+}
+
+// runFixture 는 대진 하나를 외부 프로세스들로 돌린다.
+func runFixture(f fixture, field []entrant, l limits) (matchResult, error) {
+	var bots []bot
+	defer func() {
+		for _, b := range bots {
+			b.close()
+		}
+	}()
+	for _, i := range f.Seats {
+		b, err := startBot(field[i].Command, l)
+		if err != nil {
+			return matchResult{}, fmt.Errorf("%s: %w", field[i].Name, err)
+		}
+		bots = append(bots, b)
+	}
+	return playMatch(genMap(f.Seed, len(f.Seats), 0), bots, nil), nil
+}
+
+// playAll 은 대진들을 jobs 개씩 동시에 돌린다. 결과는 fixtures 순서다.
+// each 는 한 판이 끝날 때마다 (끝난 순서대로) 부른다.
+// 에러가 나면 새 판은 더 시작하지 않고, 돌던 판들이 끝나면 첫 에러를 돌려준다.
+func playAll(fixtures []fixture, field []entrant, l limits, jobs int, each func(played)) ([]played, error) {
+	done := make([]played, len(fixtures))
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	var firstErr error
+	next := make(chan int)
+	for w := 0; w < jobs; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range next {
+				r, err := runFixture(fixtures[i], field, l)
+				mu.Lock()
+				if err != nil && firstErr == nil {
+					firstErr = err
+				}
+				done[i] = played{fixtures[i], r}
+				if err == nil && each != nil {
+					each(done[i])
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range fixtures {
+		mu.Lock()
+		failed := firstErr != nil
+		mu.Unlock()
+		if failed {
+			break
+		}
+		next <- i
+	}
+	close(next)
+	wg.Wait()
+	return done, firstErr
+}
+
+// pairOutcome 은 한 판 안에서 두 봇의 맞대결 결과 (a 가 얻은 점수 1, 0.5, 0)
+type pairOutcome struct {
+	a, b  int
+	score float64
+}
+
+// outcomes 는 순위를 짝마다의 승/무/패로 바꾼다. 같은 봇끼리는 세지 않는다.
+func (p played) outcomes() []pairOutcome {
+	var result []pairOutcome
+	for i := range p.Seats {
+		for j := i + 1; j < len(p.Seats); j++ {
+			a, b := p.Seats[i], p.Seats[j]
+			if a == b {
+				continue
+			}
+			ra, rb := p.Result.Seats[i].Rank, p.Result.Seats[j].Rank
+			score := 0.5
+			if ra < rb {
+				score = 1
+			} else if ra > rb {
+				score = 0
+			}
+			result = append(result, pairOutcome{a, b, score})
+		}
+	}
+	return result
+}
+
+// fitElo 는 짝 결과들에 맞는 Elo 를 Bradley-Terry 최대우도로 구한다. (MM 반복, 비기면 반승)
+// 전승/전패여도 값이 튀지 않게 봇마다 가상의 상대와 한번 비긴 것으로 친다.
+// 평균은 1500 으로 맞춘다.
+func fitElo(games []played, n int) []float64 {
+	var pairs []pairOutcome
+	for _, g := range games {
+		pairs = append(pairs, g.outcomes()...)
+	}
+	gamma := make([]float64, n)
+	for i := range gamma {
+		gamma[i] = 1
+	}
+	wins := make([]float64, n)
+	denom := make([]float64, n)
+	for iter := 0; iter < 200; iter++ {
+		for i := range gamma {
+			wins[i], denom[i] = 0.5, 1/(gamma[i]+1)
+		}
+		for _, p := range pairs {
+			d := 1 / (gamma[p.a] + gamma[p.b])
+			wins[p.a] += p.score
+			wins[p.b] += 1 - p.score
+			denom[p.a] += d
+			denom[p.b] += d
+		}
+		for i := range gamma {
+			gamma[i] = wins[i] / denom[i]
+		}
+	}
+	elo := make([]float64, n)
+	for i, g := range gamma {
+		elo[i] = 400 * math.Log10(g)
+	}
+	mean := 0.0
+	for _, r := range elo {
+		mean += r
+	}
+	mean /= float64(n)
+	for i := range elo {
+		elo[i] += 1500 - mean
+	}
+	return elo
+}
+
+// eloInterval 은 판들을 다시 뽑는 bootstrap 으로 95% 구간을 구한다.
+func eloInterval(games []played, n int, seed int64, rounds int) (lo, hi []float64) {
+	rng := rand.New(rand.NewSource(seed))
+	samples := make([][]float64, n)
+	resampled := make([]played, len(games))
+	for r := 0; r < rounds; r++ {
+		for i := range resampled {
+			resampled[i] = games[rng.Intn(len(games))]
+		}
+		for i, e := range fitElo(resampled, n) {
+			samples[i] = append(samples[i], e)
+		}
+	}
+	lo, hi = make([]float64, n), make([]float64, n)
+	for i, s := range samples {
+		sort.Float64s(s)
+		lo[i] = s[int(0.025*float64(len(s)-1))]
+		hi[i] = s[int(0.975*float64(len(s)-1))]
+	}
+	return
+}
+
+// standing 은 봇 하나의 누적 성적
+type standing struct {
+	games, wins int
+	rankSum     int
+	boxes       int
+	deaths      map[string]int
+}
+
+func summarize(games []played, n int) []standing {
+	st := make([]standing, n)
+	for i := range st {
+		st[i].deaths = map[string]int{}
+	}
+	for _, g := range games {
+		for seat, b := range g.Seats {
+			r := g.Result.Seats[seat]
+			s := &st[b]
+			s.games++
+			s.rankSum += r.Rank
+			s.boxes += r.Boxes
+			if r.Rank == 1 {
+				s.wins++
+			}
+			if r.Death != "" {
+				s.deaths[r.Death]++
+			}
+		}
+	}
+	return st
+}
+
+func (s standing) deathSummary() string {
+	var causes []string
+	for c := range s.deaths {
+		causes = append(causes, c)
+	}
+	sort.Strings(causes)
+	var parts []string
+	for _, c := range causes {
+		parts = append(parts, fmt.Sprintf("%s %d", c, s.deaths[c]))
+	}
+	if len(parts) == 0 {
+		return "-"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// tournamentCommand 는 봇들끼리 여러 판을 병렬로 돌리고 성적을 낸다.
+//
+//	hypersonic tournament -bot a=./hypersonic -bot f="./hypersonic -bot farmer" [-games 200] [-players 2,4]
+func tournamentCommand(args []string) {
+	fs := flag.NewFlagSet("tournament", flag.ExitOnError)
+	var field entrants
+	fs.Var(&field, "bot", "entrant as `name=command` (repeatable; default: two copies of this binary)")
+	games := fs.Int("games", 100, "number of matches")
+	sizeList := fs.String("players", "2,4", "comma separated player counts, used in turn")
+	// 한 판에 봇 프로세스가 maxPlayers 개까지 뜨니까 그만큼 나눠서 돌린다.
+	jobs := fs.Int("j", max(1, runtime.NumCPU()/maxPlayers), "matches to run in parallel")
+	seed := fs.Int64("seed", 1, "seed for the schedule and maps")
+	league := fs.String("league", "bronze", "league rules: "+leagueNames())
+	results := fs.String("o", "", "write every match result as JSON lines to `file`")
+	verbose := fs.Bool("v", false, "print each match result")
+	lim := officialLimits
+	lim.addFlags(fs)
+	fs.Parse(args)
+
+	if err := setLeague(*league); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if len(field) == 0 {
+		self, err := os.Executable()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		field = entrants{{"a", self}, {"b", self}}
+	}
+	var sizes []int
+	for _, f := range strings.Split(*sizeList, ",") {
+		n, err := strconv.Atoi(strings.TrimSpace(f))
+		if err != nil || n < 2 || n > maxPlayers {
+			fmt.Fprintf(os.Stderr, "tournament: bad player count %q\n", f)
+			os.Exit(2)
+		}
+		sizes = append(sizes, n)
+	}
+
+	fixtures := schedule(*seed, *games, sizes, len(field))
+	done, err := playAll(fixtures, field, lim, *jobs, func(p played) {
+		if *verbose {
+			fmt.Fprintln(os.Stderr, p.Result)
+		}
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	if *results != "" {
+		f, err := os.Create(*results)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		enc := json.NewEncoder(f)
+		for _, p := range done {
+			enc.Encode(struct {
+				Bots []string `json:"bots"`
+				matchResult
+			}{p.names(field), p.Result})
+		}
+		f.Close()
+	}
+
+	elo := fitElo(done, len(field))
+	lo, hi := eloInterval(done, len(field), *seed, 200)
+	st := summarize(done, len(field))
+	tw := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "bot\tgames\twin%\tavg rank\telo\t95% ci\tboxes/game\tdeaths")
+	for i, e := range field {
+		s := st[i]
+		if s.games == 0 {
+			fmt.Fprintf(tw, "%s\t0\t-\t-\t-\t-\t-\t-\n", e.Name)
+			continue
+		}
+		g := float64(s.games)
+		fmt.Fprintf(tw, "%s\t%d\t%.1f\t%.2f\t%.0f\t[%.0f, %.0f]\t%.1f\t%s\n",
+			e.Name, s.games, 100*float64(s.wins)/g, float64(s.rankSum)/g,
+			elo[i], lo[i], hi[i], float64(s.boxes)/g, s.deathSummary())
+	}
+	tw.Flush()
+}
+
+func (p played) names(field []entrant) []string {
+	var names []string
+	for _, i := range p.Seats {
+		names = append(names, field[i].Name)
+	}
+	return names
+}
@@ -0,0 +1,113 @@
+//go:build !arena
+
+package main
+
+import (
+	"math"
+	"testing"
+)
+
+// duels 는 a 가 b 를 상대로 wins 번 이기고, losses 번 지고, draws 번 비긴 2인 판들
+func duels(a, b, wins, losses, draws int) []played {
+	var games []played
+	add := func(ra, rb, n int) {
+		for i := 0; i < n; i++ {
+			games = append(games, played{
+				fixture{Seats: []int{a, b}},
+				matchResult{Seats: []seatResult{{Rank: ra}, {Rank: rb}}},
+			})
+		}
+	}
+	add(1, 2, wins)
+	add(2, 1, losses)
+	add(1, 1, draws)
+	return games
+}
+
+func TestFitElo(t *testing.T) {
+	tests := []struct {
+		name  string
+		n     int
+		games []played
+		diff  [][3]float64 // {i, j, elo[i]-elo[j]}
+		tol   float64
+	}{
+		{"even", 2, duels(0, 1, 50, 50, 0), [][3]float64{{0, 1, 0}}, 0.01},
+		{"draws", 2, duels(0, 1, 0, 0, 40), [][3]float64{{0, 1, 0}}, 0.01},
+		// 3:1 이면 400*log10(3) = 190.8 (가상의 무승부 때문에 조금 작다)
+		{"3:1", 2, duels(0, 1, 750, 250, 0), [][3]float64{{0, 1, 190.8}}, 2},
+		{"1:3", 2, duels(0, 1, 250, 750, 0), [][3]float64{{0, 1, -190.8}}, 2},
+		// Bradley-Terry 에서는 차이가 더해진다.
+		{"chain", 3, append(duels(0, 1, 750, 250, 0), duels(1, 2, 750, 250, 0)...),
+			[][3]float64{{0, 1, 190.8}, {1, 2, 190.8}, {0, 2, 381.6}}, 4},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			elo := fitElo(tt.games, tt.n)
+			mean := 0.0
+			for _, e := range elo {
+				mean += e
+			}
+			if mean /= float64(tt.n); math.Abs(mean-1500) > 1e-6 {
+				t.Errorf("mean %.2f, want 1500", mean)
+			}
+			for _, d := range tt.diff {
+				i, j := int(d[0]), int(d[1])
+				if got := elo[i] - elo[j]; math.Abs(got-d[2]) > tt.tol {
+					t.Errorf("elo[%d]-elo[%d] = %.1f, want %.1f", i, j, got, d[2])
+				}
+			}
+		})
+	}
+}
+
+// TestFitEloSweep 은 전승이어도 값이 유한한지 본다.
+func TestFitEloSweep(t *testing.T) {
+	elo := fitElo(duels(0, 1, 10, 0, 0), 2)
+	if d := elo[0] - elo[1]; math.IsInf(d, 0) || math.IsNaN(d) || d < 200 {
+		t.Errorf("sweep elo %v", elo)
+	}
+}
+
+func TestOutcomes(t *testing.T) {
+	// 4인 판: 봇 2 가 1등, 봇 0 과 1 이 같이 2등, 봇 0 이 또 한 자리에서 4등
+	p := played{
+		fixture{Seats: []int{0, 1, 2, 0}},
+		matchResult{Seats: []seatResult{{Rank: 2}, {Rank: 2}, {Rank: 1}, {Rank: 4}}},
+	}
+	want := []pairOutcome{
+		{0, 1, 0.5}, {0, 2, 0},
+		{1, 2, 0}, {1, 0, 1},
+		{2, 0, 1},
+	}
+	got := p.outcomes()
+	if len(got) != len(want) {
+		t.Fatalf("outcomes %v, want %v", got, want)
+	}
+	for i := range want {
+		if got[i] != want[i] {
+			t.Errorf("outcomes[%d] = %v, want %v", i, got[i], want[i])
+		}
+	}
+}
+
+// TestPlayAllStopsOnError 는 봇을 못 띄우면 남은 대진을 더 돌리지 않고 에러를 돌려주는지 본다.
+func TestPlayAllStopsOnError(t *testing.T) {
+	field := entrants{{"missing", "/nonexistent/bot"}}
+	fixtures := schedule(1, 50, []int{2}, len(field))
+	jobs := 2
+	done, err := playAll(fixtures, field, limits{}, jobs, func(played) { t.Error("finished a match") })
+	if err == nil {
+		t.Fatal("no error")
+	}
+	started := 0
+	for _, p := range done {
+		if p.Seats != nil {
+			started++
+		}
+	}
+	// 에러를 알기 전에 이미 넘긴 대진은 돌 수 있다.
+	if started > 2*jobs {
+		t.Errorf("started %d of %d matches after the first error", started, len(fixtures))
+	}
+}
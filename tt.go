@@ -0,0 +1,110 @@
+package main
+
+// transposition table. 탐색에서 다른 순서로 같은 world 에 오는 일이 많아서
+// zobrist 키로 점수와 가장 좋았던 수를 기억해둔다.
+// 크기가 정해져 있어서 같은 칸에 다른 키가 오면 덮어쓴다.
+
+// ttBits 는 표 크기 (1<<ttBits 칸)
+const ttBits = 16
+
+// 저장된 점수의 종류. alpha-beta 에서 잘렸으면 정확한 값이 아니라 한쪽 경계다.
+const (
+	ttExact = iota + 1
+	ttLower // 점수는 적어도 value
+	ttUpper // 점수는 많아야 value
+)
+
+type ttEntry struct {
+	key   uint64
+	value int32
+	depth int8
+	flag  uint8
+	gen   uint16 // 저장한 탐색. 점수는 같은 탐색에서만 쓴다
+	move  action
+}
+
+type transTable struct {
+	entries []ttEntry
+	mask    uint64
+	gen     uint16
+
+	probes, hits int
+}
+
+func newTransTable(bits uint) *transTable {
+	return &transTable{entries: make([]ttEntry, 1<<bits), mask: 1<<bits - 1}
+}
+
+// newSearch 는 새 탐색을 시작한다. 지난 탐색의 점수는 버리고 수만 순서 정하는 데 쓴다.
+func (t *transTable) newSearch() {
+	t.gen++
+	t.probes, t.hits = 0, 0
+}
+
+// probe 는 key 의 항목. 없으면 nil
+func (t *transTable) probe(key uint64) *ttEntry {
+	t.probes++
+	e := &t.entries[key&t.mask]
+	if e.flag == 0 || e.key != key {
+		return nil
+	}
+	t.hits++
+	return e
+}
+
+// 키에는 턴이 없는데 이긴 점수(scoreWin - 끝난 턴)는 턴에 따라 다르다.
+// 그래서 표에는 이긴 점수를 그 노드의 턴 turn 부터 센 값으로 남기고, 꺼낼 때 다시 바꾼다.
+func ttValue(v, turn int) int32 {
+	switch {
+	case v > scoreWin/2:
+		v += turn
+	case v < -scoreWin/2:
+		v -= turn
+	}
+	return int32(v)
+}
+
+func (e *ttEntry) score(turn int) int {
+	v := int(e.value)
+	switch {
+	case v > scoreWin/2:
+		v -= turn
+	case v < -scoreWin/2:
+		v += turn
+	}
+	return v
+}
+
+// cutoff 는 e 의 점수를 turn 턴의 depth 깊이, (alpha, beta) 창에서 그대로 쓸 수 있으면 그 점수
+func (t *transTable) cutoff(e *ttEntry, turn, depth, alpha, beta int) (int, bool) {
+	if e == nil || e.gen != t.gen || int(e.depth) < depth {
+		return 0, false
+	}
+	v := e.score(turn)
+	switch {
+	case e.flag == ttExact,
+		e.flag == ttLower && v >= beta,
+		e.flag == ttUpper && v <= alpha:
+		return v, true
+	}
+	return 0, false
+}
+
+// store 는 turn 턴의 노드에서 alpha, beta 창으로 구한 점수 v 와 가장 좋은 수를 남긴다.
+// 지난 탐색의 항목이나 더 얕게 본 항목은 덮어쓴다.
+func (t *transTable) store(key uint64, turn, depth, alpha, beta, v int, move action) {
+	e := &t.entries[key&t.mask]
+	if e.flag != 0 && e.gen == t.gen && int(e.depth) > depth {
+		return
+	}
+	flag := uint8(ttExact)
+	if v <= alpha {
+		flag = ttUpper
+	} else if v >= beta {
+		flag = ttLower
+	}
+	*e = ttEntry{key, ttValue(v, turn), int8(depth), flag, t.gen, move}
+}
+
+// duelTT 는 duel 탐색이 쓰는 표. 처음 쓸 때 만든다.
+var duelTT *transTable
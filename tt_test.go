@@ -0,0 +1,59 @@
+package main
+
+import "testing"
+
+// TestTTWinScore 는 이긴 점수를 다른 턴의 같은 상태에서 꺼내면
+// 그 턴에서 센 점수로 바뀌는지 본다.
+func TestTTWinScore(t *testing.T) {
+	tests := []struct {
+		name     string
+		v, turn  int // 저장한 점수와 턴
+		readTurn int
+		want     int
+	}{
+		{"win", scoreWin - 5, 3, 1, scoreWin - 3},
+		{"loss", -scoreWin + 5, 3, 1, -scoreWin + 3},
+		{"same turn", scoreWin - 5, 3, 3, scoreWin - 5},
+		{"boxes", 300, 3, 1, 300},
+		{"doom", -scoreDoom + 2, 3, 1, -scoreDoom + 2},
+	}
+	for _, tt := range tests {
+		tab := newTransTable(4)
+		tab.newSearch()
+		tab.store(42, tt.turn, 2, -scoreWin*2, scoreWin*2, tt.v, action{})
+		v, ok := tab.cutoff(tab.probe(42), tt.readTurn, 2, -scoreWin*2, scoreWin*2)
+		if !ok || v != tt.want {
+			t.Errorf("%s: got %d %v, want %d", tt.name, v, ok, tt.want)
+		}
+	}
+}
+
+func TestTTBounds(t *testing.T) {
+	tests := []struct {
+		name                string
+		v, alpha, beta      int // 저장할 때
+		readAlpha, readBeta int
+		ok                  bool
+	}{
+		{"exact", 5, 0, 10, 100, 200, true},
+		{"lower cut", 20, 0, 10, 0, 15, true},
+		{"lower no cut", 20, 0, 10, 0, 30, false},
+		{"upper cut", -5, 0, 10, 0, 10, true},
+		{"upper no cut", -5, 0, 10, -10, 10, false},
+	}
+	for _, tt := range tests {
+		tab := newTransTable(4)
+		tab.newSearch()
+		tab.store(7, 0, 3, tt.alpha, tt.beta, tt.v, action{})
+		if _, ok := tab.cutoff(tab.probe(7), 0, 3, tt.readAlpha, tt.readBeta); ok != tt.ok {
+			t.Errorf("%s: cutoff %v, want %v", tt.name, ok, tt.ok)
+		}
+		if _, ok := tab.cutoff(tab.probe(7), 0, 4, tt.readAlpha, tt.readBeta); ok {
+			t.Errorf("%s: used a shallower entry", tt.name)
+		}
+		tab.newSearch()
+		if _, ok := tab.cutoff(tab.probe(7), 0, 3, tt.readAlpha, tt.readBeta); ok {
+			t.Errorf("%s: used an entry from the last search", tt.name)
+		}
+	}
+}
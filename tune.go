@@ -0,0 +1,289 @@
+//go:build !arena
+
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"math"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"runtime"
+	"time"
+)
+
+// tune 은 SPSA 로 가중치를 맞춘다.
+// 반복마다 모든 가중치를 무작위 방향(±1)으로 조금씩 민 두 벌(+, -)을 만들어 서로 붙이고,
+// 이긴 쪽으로 가중치를 옮긴다. 판 결과가 시끄러워도 반복이 쌓이면 좋은 쪽으로 간다.
+//
+// 가중치는 tuneSteps 로 나눈 값(θ)으로 다룬다. θ 가 1 움직이면 가중치는 한 step 만큼 움직인다.
+// ItemHorizon 은 θ 의 마지막 값이고, 쓸 때 반올림한다.
+
+// tuneSteps 는 특징마다 한번에 밀어보는 크기. (마지막은 ItemHorizon)
+var tuneSteps = [numFeatures + 1]float64{
+	featBox:       0.5,
+	featItemBox:   0.5,
+	featItem:      2,
+	featTurns:     0.1,
+	featMobility:  0.05,
+	featDanger:    0.5,
+	featEnemyDist: 0.05,
+	featTerritory: 0.02,
+	numFeatures:   1,
+}
+
+// SPSA 이득 수열 (Spall 이 권하는 지수)
+const (
+	spsaAlpha = 0.602
+	spsaGamma = 0.101
+)
+
+// tuneCheckpoint 는 tune 을 멈췄다가 이어서 할 수 있게 남기는 상태.
+// 반복마다 쓰는 난수는 Seed 와 반복 번호로 정해서 난수 상태는 남기지 않는다.
+type tuneCheckpoint struct {
+	Seed      int64       `json:"seed"`
+	Iteration int         `json:"iteration"`
+	BigA      float64     `json:"bigA"` // 처음 -iterations 로 정한다. 이어서 할 때 바꿔도 이득 수열은 그대로다
+	Theta     []float64   `json:"theta"`
+	Base      Weights     `json:"base"`
+	History   []tuneRound `json:"history"`
+}
+
+// tuneRound 는 반복 하나의 기록. Score 는 + 쪽이 - 쪽을 상대로 얻은 점수 (-1 ~ 1)
+type tuneRound struct {
+	Iteration int     `json:"iteration"`
+	Score     float64 `json:"score"`
+	Games     int     `json:"games"`
+	Weights   Weights `json:"weights"`
+}
+
+func thetaOf(w Weights) []float64 {
+	theta := make([]float64, numFeatures+1)
+	for f := range w.W {
+		theta[f] = w.W[f] / tuneSteps[f]
+	}
+	theta[numFeatures] = float64(w.ItemHorizon) / tuneSteps[numFeatures]
+	return theta
+}
+
+func weightsOf(theta []float64) Weights {
+	var w Weights
+	for f := range w.W {
+		w.W[f] = theta[f] * tuneSteps[f]
+	}
+	w.ItemHorizon = int(math.Max(0, math.Round(theta[numFeatures]*tuneSteps[numFeatures])))
+	return w
+}
+
+func writeJSON(path string, v interface{}) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	// 쓰다가 멈춰도 이전 파일이 남도록 다른 이름으로 쓰고 바꾼다.
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, append(data, '\n'), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func loadCheckpoint(path string) (*tuneCheckpoint, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var c tuneCheckpoint
+	if err := json.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	if len(c.Theta) != int(numFeatures)+1 {
+		return nil, fmt.Errorf("%s: want %d parameters, got %d", path, numFeatures+1, len(c.Theta))
+	}
+	return &c, nil
+}
+
+// tuner 는 tune 한 번의 설정
+type tuner struct {
+	self    string // 이 실행 파일
+	dir     string // 가중치 파일들을 쓰는 곳
+	games   int    // 반복마다 맵 수. 맵마다 자리를 바꿔서 두 판씩 한다
+	players int
+	nodes   int
+	league  string
+	limits  limits
+	jobs    int
+	a, c    float64 // 이득 수열의 처음 크기
+	bigA    float64 // a 수열이 처음에 너무 크지 않게 더하는 값
+}
+
+// fixtures 는 반복 k 의 대진. 봇 0 이 +, 봇 1 이 - 다.
+// 2인이면 [+,-] 와 [-,+], 4인이면 [+,-,+,-] 와 [-,+,-,+] 로 같은 맵을 두 번 한다.
+func (t *tuner) fixtures(seed int64, k int) []fixture {
+	var result []fixture
+	for g := 0; g < t.games; g++ {
+		mapSeed := seed*1000003 + int64(k)*1009 + int64(g) + 1
+		for swap := 0; swap < 2; swap++ {
+			seats := make([]int, t.players)
+			for i := range seats {
+				seats[i] = (i + swap) % 2
+			}
+			result = append(result, fixture{mapSeed, seats})
+		}
+	}
+	return result
+}
+
+func (t *tuner) command(name string, w Weights) (string, error) {
+	path := filepath.Join(t.dir, name+".json")
+	if err := writeJSON(path, w); err != nil {
+		return "", err
+	}
+	return fmt.Sprintf("%s -weights %s -nodes %d -league %s", t.self, path, t.nodes, t.league), nil
+}
+
+// step 은 반복 k 를 한다. θ 를 고치고 그 반복의 기록을 돌려준다.
+func (t *tuner) step(seed int64, k int, theta []float64) (tuneRound, error) {
+	rng := rand.New(rand.NewSource(seed*7919 + int64(k)))
+	ak := t.a / math.Pow(float64(k+1)+t.bigA, spsaAlpha)
+	ck := t.c / math.Pow(float64(k+1), spsaGamma)
+
+	delta := make([]float64, len(theta))
+	plus := make([]float64, len(theta))
+	minus := make([]float64, len(theta))
+	for i := range theta {
+		delta[i] = float64(2*rng.Intn(2) - 1)
+		plus[i] = theta[i] + ck*delta[i]
+		minus[i] = theta[i] - ck*delta[i]
+	}
+	plusCmd, err := t.command("plus", weightsOf(plus))
+	if err != nil {
+		return tuneRound{}, err
+	}
+	minusCmd, err := t.command("minus", weightsOf(minus))
+	if err != nil {
+		return tuneRound{}, err
+	}
+	field := []entrant{{"plus", plusCmd}, {"minus", minusCmd}}
+	done, err := playAll(t.fixtures(seed, k), field, t.limits, t.jobs, nil)
+	if err != nil {
+		return tuneRound{}, err
+	}
+
+	// 판들은 대진 순서대로 더해서 결과가 끝난 순서에 따라 바뀌지 않게 한다.
+	total, n := 0.0, 0
+	for _, p := range done {
+		for _, o := range p.outcomes() {
+			if o.a == 0 {
+				total += 2*o.score - 1
+			} else {
+				total += 1 - 2*o.score
+			}
+			n++
+		}
+	}
+	score := 0.0
+	if n > 0 {
+		score = total / float64(n)
+	}
+	for i := range theta {
+		theta[i] += ak * score / (2 * ck * delta[i])
+	}
+	return tuneRound{k, score, len(done), weightsOf(theta)}, nil
+}
+
+// tuneCommand 는 자체 대전으로 가중치를 맞춘다. checkpoint 가 있으면 이어서 한다.
+//
+//	hypersonic tune [-iterations 500] [-games 4] [-o best.json] [-checkpoint tune.json]
+func tuneCommand(args []string) {
+	fs := flag.NewFlagSet("tune", flag.ExitOnError)
+	iterations := fs.Int("iterations", 500, "stop after `n` SPSA iterations in total")
+	games := fs.Int("games", 4, "maps per iteration (each played twice with seats swapped)")
+	players := fs.Int("players", 2, "players per match (2 or 4)")
+	seed := fs.Int64("seed", 1, "seed for perturbations and maps")
+	jobs := fs.Int("j", runtime.NumCPU(), "matches to run in parallel")
+	nodes := fs.Int("nodes", 20000, "duel search node budget per turn, so matches are reproducible")
+	league := fs.String("league", "bronze", "league rules: "+leagueNames())
+	base := fs.String("base", "", "start from the weights in JSON `file` (default: built-in weights.json)")
+	out := fs.String("o", "best.json", "write the latest SPSA weights to `file` after every iteration")
+	ckpt := fs.String("checkpoint", "tune.json", "save and resume progress from `file`")
+	a := fs.Float64("a", 2, "SPSA step size at the start")
+	c := fs.Float64("c", 1, "SPSA perturbation size at the start (in steps of each weight)")
+	// CPU 를 나눠 쓰면 노드 수로 멈춰도 느려질 수 있어서 공식 제한보다 넉넉하게 둔다.
+	lim := limits{5 * time.Second, time.Second}
+	lim.addFlags(fs)
+	fs.Parse(args)
+
+	// dir 은 아래에서 만드는 임시 디렉터리. os.Exit 는 defer 를 부르지 않으니 여기서 지운다.
+	var dir string
+	fail := func(err error) {
+		if dir != "" {
+			os.RemoveAll(dir)
+		}
+		fmt.Fprintln(os.Stderr, "tune:", err)
+		os.Exit(1)
+	}
+	if err := setLeague(*league); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(2)
+	}
+	if *players != 2 && *players != 4 {
+		fmt.Fprintln(os.Stderr, "tune: -players must be 2 or 4")
+		os.Exit(2)
+	}
+	self, err := os.Executable()
+	if err != nil {
+		fail(err)
+	}
+
+	state, err := loadCheckpoint(*ckpt)
+	switch {
+	case err == nil:
+		if state.Seed != *seed {
+			fail(fmt.Errorf("%s was made with -seed %d", *ckpt, state.Seed))
+		}
+		fmt.Fprintf(os.Stderr, "tune: resuming from %s at iteration %d\n", *ckpt, state.Iteration)
+	case errors.Is(err, os.ErrNotExist):
+		start := weights
+		if *base != "" {
+			if start, err = loadWeights(*base, weights); err != nil {
+				fail(err)
+			}
+		}
+		state = &tuneCheckpoint{Seed: *seed, BigA: float64(*iterations) / 10, Theta: thetaOf(start), Base: start}
+	default:
+		fail(err)
+	}
+
+	dir, err = os.MkdirTemp("", "hypersonic-tune")
+	if err != nil {
+		fail(err)
+	}
+	defer os.RemoveAll(dir)
+	t := &tuner{
+		self: self, dir: dir, games: *games, players: *players, nodes: *nodes,
+		league: rules.League, limits: lim, jobs: *jobs,
+		a: *a, c: *c, bigA: state.BigA,
+	}
+
+	for state.Iteration < *iterations {
+		start := time.Now()
+		r, err := t.step(state.Seed, state.Iteration, state.Theta)
+		if err != nil {
+			fail(err)
+		}
+		state.Iteration++
+		state.History = append(state.History, r)
+		if err := writeJSON(*out, r.Weights); err != nil {
+			fail(err)
+		}
+		if err := writeJSON(*ckpt, state); err != nil {
+			fail(err)
+		}
+		fmt.Fprintf(os.Stderr, "tune: iteration %d score %+.2f (%d games, %s)\n",
+			r.Iteration+1, r.Score, r.Games, time.Since(start).Round(time.Second))
+	}
+}
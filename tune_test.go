@@ -0,0 +1,24 @@
+//go:build !arena
+
+package main
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// TestCheckpointBigA 는 checkpoint 에 남긴 bigA 를 다시 읽는지 본다.
+func TestCheckpointBigA(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tune.json")
+	want := &tuneCheckpoint{Seed: 3, Iteration: 7, BigA: 50, Theta: thetaOf(defaultWeights), Base: defaultWeights}
+	if err := writeJSON(path, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := loadCheckpoint(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.BigA != want.BigA || got.Iteration != want.Iteration || got.Seed != want.Seed {
+		t.Errorf("loaded %+v, want %+v", got, want)
+	}
+}
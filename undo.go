@@ -0,0 +1,134 @@
+package main
+
+// world 를 바꾸는 방법들. 바꾸면서 hash 를 고치고, 되돌릴 수 있게 log 에 남긴다.
+// 탐색은 step 으로 한 턴 가보고 undo 로 돌아오기 때문에 world 를 복사하지 않는다.
+//
+//	m := w.mark()
+//	w.step(acts)
+//	...
+//	w.undo(m)
+
+// 되돌릴 것의 종류
+const (
+	undoTurn = iota
+	undoCell
+	undoPlayer
+	undoAddBomb
+	undoRemoveBomb
+	undoCountDown
+	undoAddItem
+	undoRemoveItem
+)
+
+// undoEntry 는 바꾸기 전의 값 하나. kind 에 따라 쓰는 필드가 다르다.
+type undoEntry struct {
+	kind  uint8
+	i     int // players, bombs, items 안의 위치
+	hash  uint64
+	n     int // t, 칸의 값, CountDown
+	pos   Pos
+	p     Player
+	dead  int
+	boxes int
+	bomb  Bomb
+	item  Item
+}
+
+// mark 는 지금 log 의 위치. undo(mark) 로 여기까지 되돌린다.
+func (w *world) mark() int {
+	return len(w.log)
+}
+
+// undo 는 mark 뒤에 바뀐 것들을 거꾸로 되돌린다.
+func (w *world) undo(mark int) {
+	for k := len(w.log) - 1; k >= mark; k-- {
+		e := &w.log[k]
+		switch e.kind {
+		case undoTurn:
+			w.t = e.n
+		case undoCell:
+			w.board[e.pos.Y][e.pos.X] = e.n
+		case undoPlayer:
+			w.players[e.i], w.dead[e.i], w.boxes[e.i] = e.p, e.dead, e.boxes
+		case undoAddBomb:
+			w.bombs = w.bombs[:len(w.bombs)-1]
+		case undoRemoveBomb:
+			// 지울 때 마지막 폭탄을 i 자리로 옮겼으니 다시 뒤로 보낸다.
+			if e.i == len(w.bombs) {
+				w.bombs = append(w.bombs, e.bomb)
+			} else {
+				w.bombs = append(w.bombs, w.bombs[e.i])
+				w.bombs[e.i] = e.bomb
+			}
+		case undoCountDown:
+			w.bombs[e.i].CountDown = e.n
+		case undoAddItem:
+			w.items = w.items[:len(w.items)-1]
+		case undoRemoveItem:
+			if e.i == len(w.items) {
+				w.items = append(w.items, e.item)
+			} else {
+				w.items = append(w.items, w.items[e.i])
+				w.items[e.i] = e.item
+			}
+		}
+		w.hash = e.hash
+	}
+	w.log = w.log[:mark]
+}
+
+func (w *world) setTurn(t int) {
+	w.log = append(w.log, undoEntry{kind: undoTurn, hash: w.hash, n: w.t})
+	w.t = t
+}
+
+func (w *world) setCell(p Pos, c int) {
+	w.log = append(w.log, undoEntry{kind: undoCell, hash: w.hash, pos: p, n: w.board[p.Y][p.X]})
+	w.hash ^= cellKey(p, w.board[p.Y][p.X]) ^ cellKey(p, c)
+	w.board[p.Y][p.X] = c
+}
+
+// setPlayer 는 players[i] 와 그 플레이어의 dead, boxes 를 바꾼다.
+func (w *world) setPlayer(i int, p Player, dead, boxes int) {
+	w.log = append(w.log, undoEntry{kind: undoPlayer, i: i, hash: w.hash, p: w.players[i], dead: w.dead[i], boxes: w.boxes[i]})
+	w.hash ^= w.playerKey(i)
+	w.players[i], w.dead[i], w.boxes[i] = p, dead, boxes
+	w.hash ^= w.playerKey(i)
+}
+
+func (w *world) addBomb(b Bomb) {
+	w.log = append(w.log, undoEntry{kind: undoAddBomb, hash: w.hash})
+	w.hash ^= bombKey(b)
+	w.bombs = append(w.bombs, b)
+}
+
+func (w *world) setCountDown(i, countDown int) {
+	w.log = append(w.log, undoEntry{kind: undoCountDown, i: i, hash: w.hash, n: w.bombs[i].CountDown})
+	w.hash ^= bombKey(w.bombs[i])
+	w.bombs[i].CountDown = countDown
+	w.hash ^= bombKey(w.bombs[i])
+}
+
+// removeBomb 은 bombs[i] 를 지운다. 마지막 폭탄이 i 자리로 온다.
+func (w *world) removeBomb(i int) {
+	w.log = append(w.log, undoEntry{kind: undoRemoveBomb, i: i, hash: w.hash, bomb: w.bombs[i]})
+	w.hash ^= bombKey(w.bombs[i])
+	last := len(w.bombs) - 1
+	w.bombs[i] = w.bombs[last]
+	w.bombs = w.bombs[:last]
+}
+
+func (w *world) addItem(it Item) {
+	w.log = append(w.log, undoEntry{kind: undoAddItem, hash: w.hash})
+	w.hash ^= itemKey(it)
+	w.items = append(w.items, it)
+}
+
+// removeItem 은 items[i] 를 지운다. 마지막 아이템이 i 자리로 온다.
+func (w *world) removeItem(i int) {
+	w.log = append(w.log, undoEntry{kind: undoRemoveItem, i: i, hash: w.hash, item: w.items[i]})
+	w.hash ^= itemKey(w.items[i])
+	last := len(w.items) - 1
+	w.items[i] = w.items[last]
+	w.items = w.items[:last]
+}
@@ -0,0 +1,114 @@
+//go:build !arena
+
+package main
+
+import (
+	"fmt"
+	"testing"
+)
+
+// dump 는 비교용으로 world 의 상태를 모두 적은 것. 버퍼와 log 는 빼고 순서는 그대로 둔다.
+func dump(w *world) string {
+	return fmt.Sprint(w.t, w.board, w.players, w.bombs, w.items, w.dead, w.boxes, w.hash)
+}
+
+// TestUndo 는 step 하고 undo 하면 처음과 같고, 다시 step 하면 처음 step 한 것과 같은지 본다.
+func TestUndo(t *testing.T) {
+	worldGames(t, 100, func(rf *referee, w *world, acts []action, cmds []string) {
+		before := dump(w)
+		m := w.mark()
+		w.step(acts)
+		after := dump(w)
+		w.undo(m)
+		if got := dump(w); got != before {
+			t.Fatalf("turn %d: undo\n%s\nwant\n%s", rf.turn, got, before)
+		}
+		if len(w.log) != m {
+			t.Fatalf("turn %d: log has %d entries after undo, want %d", rf.turn, len(w.log), m)
+		}
+		w.step(acts)
+		if got := dump(w); got != after {
+			t.Fatalf("turn %d: redo\n%s\nwant\n%s", rf.turn, got, after)
+		}
+	})
+}
+
+// TestSearchRestoresWorld 는 duel 탐색이 끝나면 world 가 탐색 전과 같은지 본다.
+func TestSearchRestoresWorld(t *testing.T) {
+	searched := 0
+	worldGames(t, 12, func(rf *referee, w *world, acts []action, cmds []string) {
+		if len(w.players) != 2 || w.winner() != -1 || rf.turn%4 != 0 {
+			return
+		}
+		searched++
+		before := dump(w)
+		s := duelSearch{me: 0, opp: 1, tt: newTransTable(10)}
+		for depth := 1; depth <= 3; depth++ {
+			s.root(w, depth, action{pos: w.players[0].Pos})
+			if got := dump(w); got != before {
+				t.Fatalf("turn %d depth %d: world after search\n%s\nwant\n%s", rf.turn, depth, got, before)
+			}
+		}
+	})
+	if searched == 0 {
+		t.Fatal("no 2-player positions")
+	}
+	t.Logf("%d positions", searched)
+}
+
+// escapeReference 는 플레이어 하나씩 따로 계산하던 escape. 한 번에 모두 계산하는 escape 와 비교한다.
+func escapeReference(w *world, i int, horizon int) (bool, int) {
+	bb := newBitBoard(w.board, w.bombs, w.items)
+	bs := append([]Bomb(nil), w.bombs...)
+	last := 0
+	for _, b := range bs {
+		if b.CountDown > last {
+			last = b.CountDown
+		}
+	}
+	if horizon < last {
+		horizon = last
+	}
+	var reach bitboard
+	reach.set(geo.index(w.players[i].Pos))
+	open := geo.valid.andNot(bb.walls)
+	for d := 1; ; d++ {
+		if d <= last && d < bbHorizon && rules.Kills {
+			fire := bb.explode(bs, d)
+			bb.burn(fire)
+			reach = reach.andNot(fire)
+			if reach.isZero() {
+				return false, 0
+			}
+		}
+		if d > horizon {
+			return true, reach.count()
+		}
+		free := open.andNot(bb.allBoxes()).andNot(bb.bombs)
+		reach = reach.or(geo.expand(reach).and(free))
+	}
+}
+
+// TestEscapeMatchesReference 는 eval 이 쓰는 escape 가 살아있는 플레이어마다
+// 따로 계산한 것과 같은 값을 주는지 본다.
+func TestEscapeMatchesReference(t *testing.T) {
+	worldGames(t, 100, func(rf *referee, w *world, acts []action, cmds []string) {
+		bombs := fmt.Sprint(w.bombs)
+		for _, horizon := range []int{0, duelRoom, 12} {
+			ok, room := w.escape(horizon)
+			for i := range w.players {
+				if !w.alive(i) {
+					continue
+				}
+				wantOK, wantRoom := escapeReference(w, i, horizon)
+				if ok[i] != wantOK || room[i] != wantRoom {
+					t.Fatalf("turn %d horizon %d: player %d escape %v %d, want %v %d",
+						rf.turn, horizon, i, ok[i], room[i], wantOK, wantRoom)
+				}
+			}
+		}
+		if fmt.Sprint(w.bombs) != bombs {
+			t.Fatalf("turn %d: escape changed bombs %v, was %v", rf.turn, w.bombs, bombs)
+		}
+	})
+}
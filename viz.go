@@ -0,0 +1,304 @@
+//go:build !arena
+
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+)
+
+// vizTurn 은 시각화에 넣는 한 턴
+type vizTurn struct {
+	State     *State          `json:"state"`
+	Decision  decision        `json:"decision"`
+	Explosion []Pos           `json:"explosion"`
+	Stats     map[int]vizStat `json:"stats"`
+}
+
+// vizStat 은 플레이어별 누적 기록
+type vizStat struct {
+	Alive     bool `json:"alive"`
+	Bombs     int  `json:"bombs"`
+	Range     int  `json:"range"`
+	Boxes     int  `json:"boxes"`
+	BombsUsed int  `json:"bombsUsed"`
+}
+
+// buildViz 는 턴마다 결정 기록을 만들고,
+// 다음 턴에 터질 칸과 누가 상자를 몇개 부쉈는지를 센다.
+func buildViz(states []*State) []vizTurn {
+	var turns []vizTurn
+	stats := map[int]vizStat{}
+	seenBombs := map[Pos]bool{}
+	for i, s := range states {
+		d := explain(s, i+1)
+
+		alive := map[int]bool{}
+		for _, p := range s.Players() {
+			st := stats[p.ID]
+			st.Alive, st.Bombs, st.Range = true, p.Bombs, p.Range
+			stats[p.ID] = st
+			alive[p.ID] = true
+		}
+		for id, st := range stats {
+			if !alive[id] {
+				st.Alive = false
+				stats[id] = st
+			}
+		}
+		nowBombs := map[Pos]bool{}
+		for _, b := range s.Bombs() {
+			nowBombs[b.Pos] = true
+			if !seenBombs[b.Pos] {
+				st := stats[b.Owner]
+				st.BombsUsed++
+				stats[b.Owner] = st
+			}
+		}
+		seenBombs = nowBombs
+
+		// 다음 턴에 터지는 폭탄들 (연쇄 포함)
+		bs := s.Bombs()
+		bb := newBitBoard(board, bs, s.Items())
+		fire := bb.danger(bs, 1)[1]
+		var explosion []Pos
+		for f := fire; !f.isZero(); {
+			idx := f.lowest()
+			f.unset(idx)
+			explosion = append(explosion, geo.pos(idx))
+		}
+		boxes := bb.allBoxes()
+		for _, b := range bs {
+			if b.CountDown != 1 {
+				continue
+			}
+			st := stats[b.Owner]
+			st.Boxes += bb.blast(b.Pos, b.Range).and(boxes).count()
+			stats[b.Owner] = st
+		}
+
+		snapshot := map[int]vizStat{}
+		for id, st := range stats {
+			snapshot[id] = st
+		}
+		turns = append(turns, vizTurn{s, d, explosion, snapshot})
+	}
+	return turns
+}
+
+// vizCommand 는 기록된 게임을 한 파일짜리 HTML 로 만든다.
+// 외부 파일 없이 오프라인에서 열린다.
+//
+//	hypersonic viz [-o match.html] [transcript]
+func vizCommand(args []string) {
+	fs := flag.NewFlagSet("viz", flag.ExitOnError)
+	out := fs.String("o", "match.html", "write the HTML to `file`")
+	title := fs.String("title", "", "page title (default: transcript name)")
+	fs.Parse(args)
+
+	var r io.Reader = os.Stdin
+	name := "stdin"
+	if fs.NArg() > 0 {
+		f, err := os.Open(fs.Arg(0))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			os.Exit(1)
+		}
+		defer f.Close()
+		r = f
+		name = fs.Arg(0)
+	}
+	if *title == "" {
+		*title = name
+	}
+	states, err := readStates(r)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+	}
+	if len(states) == 0 {
+		fmt.Fprintln(os.Stderr, "viz: no turns")
+		os.Exit(1)
+	}
+
+	lg.out = io.Discard
+	data, err := json.Marshal(struct {
+		Title string    `json:"title"`
+		Turns []vizTurn `json:"turns"`
+	}{*title, buildViz(states)})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	page := strings.Replace(vizHTML, "/*DATA*/null", string(data), 1)
+	if err := os.WriteFile(*out, []byte(page), 0644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	fmt.Fprintf(os.Stderr, "viz: %d turns -> %s\n", len(states), *out)
+}
+
+// json.Marshal 은 <, > 를 < 로 바꾸므로 </script> 가 끼어들 일은 없다.
+const vizHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>hypersonic</title>
+<style>
+body { font-family: sans-serif; background: #1e1e1e; color: #ddd; margin: 16px; }
+#wrap { display: flex; gap: 16px; align-items: flex-start; }
+canvas { background: #2b2b2b; border: 1px solid #444; }
+#controls { margin: 8px 0; display: flex; gap: 8px; align-items: center; }
+#slider { width: 420px; }
+table { border-collapse: collapse; margin-bottom: 12px; }
+td, th { border: 1px solid #444; padding: 2px 8px; text-align: right; }
+th { background: #333; }
+pre { background: #111; padding: 8px; max-height: 420px; overflow: auto; font-size: 12px; }
+.dead { opacity: 0.4; text-decoration: line-through; }
+</style>
+</head>
+<body>
+<h3 id="title"></h3>
+<div id="controls">
+<button id="prev">&lt;</button>
+<button id="play">play</button>
+<button id="next">&gt;</button>
+<input id="slider" type="range" min="0" value="0">
+<span id="label"></span>
+</div>
+<div id="wrap">
+<canvas id="board"></canvas>
+<div>
+<table id="stats"></table>
+<pre id="trace"></pre>
+</div>
+</div>
+<script>
+var DATA = /*DATA*/null;
+var CELL = 40;
+var COLORS = ["#4fc3f7", "#e57373", "#ba68c8", "#fff176"];
+var canvas = document.getElementById("board");
+var ctx = canvas.getContext("2d");
+var slider = document.getElementById("slider");
+var cur = 0, timer = null, flash = null;
+
+document.getElementById("title").textContent = DATA.title;
+slider.max = DATA.turns.length - 1;
+
+function circle(x, y, r, color) {
+  ctx.fillStyle = color;
+  ctx.beginPath();
+  ctx.arc(x * CELL + CELL / 2, y * CELL + CELL / 2, r, 0, Math.PI * 2);
+  ctx.fill();
+}
+
+function text(x, y, s, color) {
+  ctx.fillStyle = color;
+  ctx.font = "bold 14px monospace";
+  ctx.textAlign = "center";
+  ctx.textBaseline = "middle";
+  ctx.fillText(s, x * CELL + CELL / 2, y * CELL + CELL / 2);
+}
+
+function draw(alpha) {
+  var t = DATA.turns[cur], s = t.state;
+  canvas.width = s.width * CELL;
+  canvas.height = s.height * CELL;
+  for (var y = 0; y < s.height; y++) {
+    for (var x = 0; x < s.width; x++) {
+      var c = s.board[y][x];
+      ctx.fillStyle = c == "X" ? "#555" : (c == "." ? "#2b2b2b" : "#8d6e63");
+      ctx.fillRect(x * CELL + 1, y * CELL + 1, CELL - 2, CELL - 2);
+      if (c == "1") text(x, y, "r", "#fff");
+      if (c == "2") text(x, y, "b", "#fff");
+    }
+  }
+  var path = t.decision.path || [];
+  for (var i = 0; i < path.length; i++) {
+    ctx.fillStyle = "rgba(66, 165, 245, 0.35)";
+    ctx.fillRect(path[i].X * CELL + 4, path[i].Y * CELL + 4, CELL - 8, CELL - 8);
+  }
+  s.items.forEach(function (it) {
+    circle(it.x, it.y, 8, "#66bb6a");
+    text(it.x, it.y, it.type == 1 ? "r" : "b", "#000");
+  });
+  s.bombs.forEach(function (b) {
+    circle(b.x, b.y, 14, "#000");
+    text(b.x, b.y, String(b.countdown), "#ff5252");
+  });
+  s.players.forEach(function (p) {
+    circle(p.x, p.y, 11, COLORS[p.id % 4]);
+    text(p.x, p.y, String(p.id), "#000");
+  });
+  if (alpha > 0 && cur > 0) {
+    var prev = DATA.turns[cur - 1].explosion || [];
+    ctx.fillStyle = "rgba(255, 140, 0, " + alpha + ")";
+    prev.forEach(function (p) {
+      ctx.fillRect(p.X * CELL, p.Y * CELL, CELL, CELL);
+    });
+  }
+}
+
+function panel() {
+  var t = DATA.turns[cur];
+  var rows = ["<tr><th>player</th><th>bombs</th><th>range</th><th>boxes</th><th>dropped</th></tr>"];
+  Object.keys(t.stats).sort().forEach(function (id) {
+    var st = t.stats[id];
+    rows.push("<tr class=\"" + (st.alive ? "" : "dead") + "\"><td style=\"color:" + COLORS[id % 4] + "\">P" + id +
+      (id == t.state.myId ? " (me)" : "") + "</td><td>" + st.bombs + "</td><td>" + st.range +
+      "</td><td>" + st.boxes + "</td><td>" + st.bombsUsed + "</td></tr>");
+  });
+  document.getElementById("stats").innerHTML = rows.join("");
+  var d = t.decision, lines = ["turn " + d.turn + ": " + d.choice + " (" + d.reason + ")"];
+  (d.candidates || []).forEach(function (c) {
+    var feats = Object.keys(c.features || {}).filter(function (k) { return c.features[k] != 0; })
+      .map(function (k) { return " " + k + "=" + c.features[k]; }).join("");
+    lines.push("  + " + c.kind + " (" + c.pos.X + "," + c.pos.Y + ") t=" + c.pos.Z + " score=" + c.score + feats + (c.note ? " " + c.note : ""));
+  });
+  (d.rejections || []).forEach(function (r) {
+    lines.push("  - " + r.kind + " (" + r.pos.X + "," + r.pos.Y + ") t=" + r.pos.Z + " " + r.reason);
+  });
+  document.getElementById("trace").textContent = lines.join("\n");
+  document.getElementById("label").textContent = "turn " + (cur + 1) + " / " + DATA.turns.length;
+  slider.value = cur;
+}
+
+function show(i, animate) {
+  cur = Math.max(0, Math.min(DATA.turns.length - 1, i));
+  panel();
+  if (flash) cancelAnimationFrame(flash);
+  if (!animate) { draw(0); return; }
+  var start = null;
+  function step(ts) {
+    if (start === null) start = ts;
+    var a = 1 - (ts - start) / 400;
+    draw(Math.max(0, a * 0.8));
+    if (a > 0) flash = requestAnimationFrame(step);
+  }
+  flash = requestAnimationFrame(step);
+}
+
+slider.oninput = function () { show(parseInt(slider.value, 10), false); };
+document.getElementById("prev").onclick = function () { show(cur - 1, false); };
+document.getElementById("next").onclick = function () { show(cur + 1, true); };
+document.getElementById("play").onclick = function () {
+  if (timer) { clearInterval(timer); timer = null; this.textContent = "play"; return; }
+  this.textContent = "pause";
+  timer = setInterval(function () {
+    if (cur >= DATA.turns.length - 1) { clearInterval(timer); timer = null; document.getElementById("play").textContent = "play"; return; }
+    show(cur + 1, true);
+  }, 600);
+};
+document.onkeydown = function (e) {
+  if (e.key == "ArrowLeft") show(cur - 1, false);
+  if (e.key == "ArrowRight") show(cur + 1, true);
+};
+show(0, false);
+</script>
+</body>
+</html>
+`
@@ -0,0 +1,332 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+)
+
+// world 는 탐색용으로 전역변수에서 떼어낸 게임 상태 하나.
+// step 으로 모든 플레이어의 행동을 한 턴 진행하고, undo 로 되돌린다. 규칙은 referee 와 같다.
+// 폭탄의 CountDown 은 입력과 같아서 step 을 CountDown 번 하면 터진다.
+// 탐색 중에 메모리를 잡지 않도록 버퍼들을 world 에 들고 다닌다.
+type world struct {
+	t       int
+	board   [][]int
+	players []Player
+	bombs   []Bomb
+	items   []Item
+	dead    []int // players[i] 가 죽은 턴. 0 이면 살아있다
+	boxes   []int // players[i] 가 부순 상자 수
+	// hash 는 zobrist 키. 바꿀 때는 setCell, setPlayer 같은 것을 써야 맞게 유지된다.
+	hash uint64
+	log  []undoEntry
+
+	fire   []uint8 // explode 의 칸별 불길
+	done   []bool  // explode 에서 터진 폭탄
+	bombs2 []Bomb  // escape 에서 쓰는 폭탄 사본
+}
+
+// winner 의 값. 모두 죽었으면 drawn
+const drawn = -2
+
+// burnt 는 explode 에서 불길이 지나간 칸 표시 (플레이어 비트는 그 아래를 쓴다)
+const burnt = 0x80
+
+// newWorld 는 지금 전역 상태로 world 를 만든다.
+func newWorld() *world {
+	w := &world{
+		board:   copy2D(board),
+		players: append([]Player(nil), players...),
+		bombs:   append(make([]Bomb, 0, 64), bombs...),
+		items:   append(make([]Item, 0, 64), items...),
+		dead:    make([]int, len(players)),
+		boxes:   make([]int, len(players)),
+		log:     make([]undoEntry, 0, 1024),
+		fire:    make([]uint8, width*height),
+		done:    make([]bool, 0, 64),
+		bombs2:  make([]Bomb, 0, 64),
+	}
+	w.hash = w.rehash()
+	return w
+}
+
+func (w *world) String() string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "t=%d", w.t)
+	for i, p := range w.players {
+		fmt.Fprintf(&sb, " p%d%v b%d r%d boxes=%d", p.ID, p.Pos, p.Bombs, p.Range, w.boxes[i])
+		if w.dead[i] > 0 {
+			fmt.Fprintf(&sb, " dead@%d", w.dead[i])
+		}
+	}
+	fmt.Fprintf(&sb, " bombs=%d items=%d", len(w.bombs), len(w.items))
+	return sb.String()
+}
+
+// index 는 id 플레이어의 players 안 위치. 없으면 -1
+func (w *world) index(id int) int {
+	for i, p := range w.players {
+		if p.ID == id {
+			return i
+		}
+	}
+	return -1
+}
+
+func (w *world) alive(i int) bool {
+	return w.dead[i] == 0
+}
+
+// winner 는 혼자 살아남은 플레이어 id. 아직 둘 이상 살아있으면 -1, 모두 죽었으면 drawn
+func (w *world) winner() int {
+	winner := drawn
+	for i, p := range w.players {
+		if !w.alive(i) {
+			continue
+		}
+		if winner != drawn {
+			return -1
+		}
+		winner = p.ID
+	}
+	return winner
+}
+
+func (w *world) bombAt(p Pos) bool {
+	for _, b := range w.bombs {
+		if b.Pos == p {
+			return true
+		}
+	}
+	return false
+}
+
+func (w *world) itemAt(p Pos) bool {
+	for _, it := range w.items {
+		if it.Pos == p {
+			return true
+		}
+	}
+	return false
+}
+
+// open 은 걸어 들어갈 수 있는 칸인지 (폭탄이 있으면 못 들어간다)
+func (w *world) open(p Pos) bool {
+	return inRange2D(p.X, p.Y, width, height) && w.board[p.Y][p.X] == cellFloor && !w.bombAt(p)
+}
+
+// moves 는 i 번 플레이어가 할 수 있는 행동들을 buf 에 이어 붙인다. 결과가 같은 행동은 하나만 넣는다.
+// (막힌 쪽으로 가는 것은 제자리와 같고, 폭탄을 못 놓으면 BOMB 은 MOVE 와 같다)
+func (w *world) moves(i int, buf []action) []action {
+	p := w.players[i]
+	result := buf
+	if !w.alive(i) {
+		return append(result, action{pos: p.Pos})
+	}
+	canBomb := p.Bombs > 0 && !w.bombAt(p.Pos)
+	for _, step := range []Pos{{0, 0}, {0, -1}, {1, 0}, {0, 1}, {-1, 0}} {
+		to := Pos{p.Pos.X + step.X, p.Pos.Y + step.Y}
+		if to != p.Pos && !w.open(to) {
+			continue
+		}
+		result = append(result, action{pos: to})
+		if canBomb {
+			result = append(result, action{true, to})
+		}
+	}
+	return result
+}
+
+// step 은 acts[i] 를 i 번 플레이어의 행동으로 한 턴 진행한다.
+// 순서는 referee.step 과 같다: 폭발, 폭탄 놓기, 이동, 줍기.
+func (w *world) step(acts []action) {
+	w.setTurn(w.t + 1)
+	w.explode()
+
+	for i, p := range w.players {
+		if w.alive(i) && acts[i].bomb && p.Bombs > 0 && !w.bombAt(p.Pos) {
+			w.addBomb(Bomb{Pos: p.Pos, Owner: p.ID, CountDown: rules.BombTimer, Range: p.Range})
+			p.Bombs--
+			w.setPlayer(i, p, w.dead[i], w.boxes[i])
+		}
+	}
+
+	// 한 칸씩만 움직이니까 갈 칸이 막혀있으면 제자리다.
+	// 폭탄을 먼저 다 놓고 나서 움직여야 해서 두 번 돈다.
+	var dest [zPlayers]Pos
+	for i, p := range w.players {
+		dest[i] = p.Pos
+		if w.alive(i) && acts[i].pos.adjacent(p.Pos) && w.open(acts[i].pos) {
+			dest[i] = acts[i].pos
+		}
+	}
+	for i, p := range w.players {
+		if p.Pos != dest[i] {
+			p.Pos = dest[i]
+			w.setPlayer(i, p, w.dead[i], w.boxes[i])
+		}
+	}
+
+	// 같은 칸에 여럿이 오면 모두 줍는다.
+	for k := len(w.items) - 1; k >= 0; k-- {
+		it := w.items[k]
+		picked := false
+		for i, p := range w.players {
+			if w.alive(i) && p.Pos == it.Pos {
+				rules.pickup(&p, it.Type)
+				w.setPlayer(i, p, w.dead[i], w.boxes[i])
+				picked = true
+			}
+		}
+		if picked {
+			w.removeItem(k)
+		}
+	}
+}
+
+// blast 는 폭탄 하나의 불길이 닿는 칸들에 mark 를 켠다. 불길은 referee.blast 와 같다.
+func (w *world) blast(b Bomb, mark uint8) {
+	w.fire[b.Pos.Y*width+b.Pos.X] |= mark
+	for _, dir := range []Pos{{0, -1}, {0, 1}, {-1, 0}, {1, 0}} {
+		for i := 1; i < b.Range; i++ {
+			p := Pos{b.Pos.X + dir.X*i, b.Pos.Y + dir.Y*i}
+			if !inRange2D(p.X, p.Y, width, height) || w.board[p.Y][p.X] == cellWall {
+				break
+			}
+			w.fire[p.Y*width+p.X] |= mark
+			if w.board[p.Y][p.X] != cellFloor || w.itemAt(p) || w.bombAt(p) {
+				break
+			}
+		}
+	}
+}
+
+// explode 는 카운트다운을 줄이고 터질 폭탄들을 연쇄폭발까지 터뜨린다. referee.explode 와 같다.
+func (w *world) explode() {
+	for i, b := range w.bombs {
+		w.setCountDown(i, b.CountDown-1)
+	}
+	// fire 는 칸마다 불길을 낸 폭탄 주인들의 비트 (players 의 순서).
+	// 주인이 players 에 없을 수도 있어서 burnt 비트를 같이 켠다.
+	fire := w.fire
+	for i := range fire {
+		fire[i] = 0
+	}
+	burning := false
+	done := w.done[:0]
+	for range w.bombs {
+		done = append(done, false)
+	}
+	w.done = done
+	for {
+		found := false
+		for i, b := range w.bombs {
+			if done[i] || (b.CountDown > 0 && fire[b.Pos.Y*width+b.Pos.X] == 0) {
+				continue
+			}
+			done[i], found, burning = true, true, true
+			owner := uint8(burnt)
+			if j := w.index(b.Owner); j >= 0 {
+				owner |= 1 << uint(j)
+			}
+			w.blast(b, owner)
+		}
+		if !found {
+			break
+		}
+	}
+	if !burning {
+		return
+	}
+
+	// 뒤에서부터 지워야 removeBomb 이 옮기는 폭탄이 이미 본 것이다.
+	for i := len(w.bombs) - 1; i >= 0; i-- {
+		if !done[i] {
+			continue
+		}
+		if j := w.index(w.bombs[i].Owner); j >= 0 {
+			p := w.players[j]
+			p.Bombs++
+			w.setPlayer(j, p, w.dead[j], w.boxes[j])
+		}
+		w.removeBomb(i)
+	}
+
+	for k := len(w.items) - 1; k >= 0; k-- {
+		if p := w.items[k].Pos; fire[p.Y*width+p.X] != 0 {
+			w.removeItem(k)
+		}
+	}
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			owners := fire[y*width+x]
+			c := w.board[y][x]
+			if owners == 0 || (c != cellBoxEmpty && c != cellBoxRange && c != cellBoxPlus) {
+				continue
+			}
+			w.setCell(Pos{x, y}, cellFloor)
+			for i := range w.players {
+				if owners&(1<<uint(i)) != 0 {
+					w.setPlayer(i, w.players[i], w.dead[i], w.boxes[i]+1)
+				}
+			}
+			if rules.Items && c != cellBoxEmpty {
+				w.addItem(Item{Pos{x, y}, c - cellBoxEmpty})
+			}
+		}
+	}
+
+	if !rules.Kills {
+		return
+	}
+	for i, p := range w.players {
+		if w.alive(i) && fire[p.Pos.Y*width+p.Pos.X] != 0 {
+			w.setPlayer(i, p, w.t, w.boxes[i])
+		}
+	}
+}
+
+// escape 는 플레이어들이 지금 있는 폭탄들을 (아무도 더 놓지 않는다면) 피할 수 있는지,
+// 그리고 horizon 턴 동안 안전하게 갈 수 있는 칸이 몇 개인지. 못 피하면 room 은 0 이다.
+// 턴마다 상자와 폭탄이 없어지는 것도 반영한다. 폭발은 한 번만 따라가고 모두 같이 본다.
+func (w *world) escape(horizon int) (ok [zPlayers]bool, room [zPlayers]int) {
+	bb := newBitBoard(w.board, w.bombs, w.items)
+	bs := append(w.bombs2[:0], w.bombs...)
+	w.bombs2 = bs
+	last := 0
+	for _, b := range bs {
+		if b.CountDown > last {
+			last = b.CountDown
+		}
+	}
+	if horizon < last {
+		horizon = last
+	}
+	var reach [zPlayers]bitboard
+	for i, p := range w.players {
+		if w.alive(i) {
+			reach[i].set(geo.index(p.Pos))
+		}
+	}
+	n := len(w.players)
+	open := geo.valid.andNot(bb.walls)
+	for d := 1; d <= horizon; d++ {
+		if d <= last && d < bbHorizon && rules.Kills {
+			// reach 는 d-1 번 움직인 뒤의 칸들이고, d 번째 step 에서 터진다.
+			fire := bb.explode(bs, d)
+			bb.burn(fire)
+			for i := 0; i < n; i++ {
+				reach[i] = reach[i].andNot(fire)
+			}
+		}
+		free := open.andNot(bb.allBoxes()).andNot(bb.bombs)
+		for i := 0; i < n; i++ {
+			reach[i] = reach[i].or(geo.expand(reach[i]).and(free))
+		}
+	}
+	for i := 0; i < n; i++ {
+		room[i] = reach[i].count()
+		ok[i] = room[i] > 0
+	}
+	return
+}
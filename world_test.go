@@ -0,0 +1,114 @@
+//go:build !arena
+
+package main
+
+import (
+	"fmt"
+	"math/rand"
+	"reflect"
+	"sort"
+	"testing"
+)
+
+// refWorld 는 심판의 지금 상태로 만든 world. 폭탄은 sync 하지 않은 입력 그대로 쓴다.
+func refWorld(rf *referee) *world {
+	s := rf.state(0)
+	s.apply()
+	w := newWorld()
+	w.bombs = append(w.bombs[:0], s.Bombs()...)
+	for i, p := range w.players {
+		w.boxes[i] = rf.players[p.ID].Boxes
+	}
+	w.hash = w.rehash()
+	return w
+}
+
+// randomActions 는 world 의 플레이어마다 moves 중 하나를 고르고, 같은 행동의 심판 명령도 만든다.
+func randomActions(rng *rand.Rand, w *world, n int) ([]action, []string) {
+	acts := make([]action, len(w.players))
+	cmds := make([]string, n)
+	for i := range cmds {
+		cmds[i] = "MOVE 0 0"
+	}
+	for i, p := range w.players {
+		moves := w.moves(i, nil)
+		acts[i] = moves[rng.Intn(len(moves))]
+		cmds[p.ID] = acts[i].String()
+	}
+	return acts, cmds
+}
+
+func sortedStrings[T any](xs []T) []string {
+	var result []string
+	for _, x := range xs {
+		result = append(result, fmt.Sprint(x))
+	}
+	sort.Strings(result)
+	return result
+}
+
+// worldGames 는 seed 마다 2~4 인 게임을 무작위로 끝까지 하면서 턴마다 f 를 부른다.
+// f 는 world 에서 한 턴 진행할 행동과 같은 심판 명령을 받는다.
+func worldGames(t *testing.T, seeds int, f func(rf *referee, w *world, acts []action, cmds []string)) {
+	for seed := int64(1); seed <= int64(seeds); seed++ {
+		rf := newReferee(genMap(seed, 2+int(seed%3), 0), rules)
+		rng := rand.New(rand.NewSource(seed))
+		for !rf.over() && !t.Failed() {
+			w := refWorld(rf)
+			acts, cmds := randomActions(rng, w, len(rf.players))
+			f(rf, w, acts, cmds)
+			rf.step(cmds)
+		}
+	}
+}
+
+// TestWorldStepMatchesReferee 는 world.step 이 referee.step 과 같은 다음 상태를 만드는지 본다.
+func TestWorldStepMatchesReferee(t *testing.T) {
+	worldGames(t, 200, func(rf *referee, w *world, acts []action, cmds []string) {
+		w.step(acts)
+		next := *rf
+		next.cells = copyCells(rf.cells)
+		next.players = copyPlayers(rf.players)
+		next.bombs = append([]Bomb(nil), rf.bombs...)
+		next.items = append([]Item(nil), rf.items...)
+		next.step(cmds)
+		s := next.state(0)
+
+		var alive []Player
+		for i, p := range w.players {
+			if w.alive(i) {
+				alive = append(alive, p)
+			}
+			if rp := next.players[p.ID]; rp.Boxes != w.boxes[i] || rp.Alive != w.alive(i) {
+				t.Errorf("turn %d: player %d boxes %d alive %v, referee %d %v", next.turn, p.ID, w.boxes[i], w.alive(i), rp.Boxes, rp.Alive)
+			}
+		}
+		switch {
+		case !reflect.DeepEqual(alive, s.Players()):
+			t.Errorf("turn %d: players %v, referee %v", next.turn, alive, s.Players())
+		case !reflect.DeepEqual(sortedStrings(w.bombs), sortedStrings(s.Bombs())):
+			t.Errorf("turn %d: bombs %v, referee %v", next.turn, w.bombs, s.Bombs())
+		case !reflect.DeepEqual(sortedStrings(w.items), sortedStrings(s.Items())):
+			t.Errorf("turn %d: items %v, referee %v", next.turn, w.items, s.Items())
+		case !reflect.DeepEqual(w.board, s.grid()):
+			t.Errorf("turn %d: board differs\n%v\nreferee\n%v", next.turn, w.board, s.Board)
+		}
+	})
+}
+
+func copyCells(cells [][]byte) [][]byte {
+	result := make([][]byte, len(cells))
+	for i, row := range cells {
+		result[i] = append([]byte(nil), row...)
+	}
+	return result
+}
+
+func copyPlayers(players []*refPlayer) []*refPlayer {
+	result := make([]*refPlayer, len(players))
+	for i, p := range players {
+		c := *p
+		result[i] = &c
+	}
+	return result
+}
@@ -0,0 +1,118 @@
+package main
+
+import "math/rand"
+
+// zobrist 키. world 의 칸, 플레이어, 폭탄, 아이템마다 무작위 64비트 값을 두고
+// 지금 있는 것들의 값을 모두 xor 한 것이 world.hash 다.
+// 무엇이 바뀌면 바뀌기 전 값과 바뀐 뒤 값을 xor 하면 되니까 step 에서 조금씩 고친다.
+// 칸 번호는 geo.index 를 쓴다. 벽은 바뀌지 않으니까 넣지 않는다.
+//
+// 숫자(폭탄 수, 범위, 카운트다운, 부순 상자 수)는 표 크기로 나눈 나머지를 쓴다.
+// 아주 큰 값끼리는 같은 키가 될 수 있지만 게임에서는 거의 나오지 않는다.
+const (
+	zPlayers = 4
+	zStat    = 32  // 폭탄 수, 범위
+	zTimer   = 16  // 카운트다운
+	zBoxes   = 128 // 부순 상자 수
+)
+
+var zobrist struct {
+	box    [bbBits][3]uint64 // cellBoxEmpty, cellBoxRange, cellBoxPlus 순
+	item   [bbBits][3]uint64 // item type
+	bomb   [bbBits][zTimer]uint64
+	owner  [bbBits][zPlayers]uint64
+	brange [bbBits][zStat]uint64
+	act    [bbBits][2]uint64 // 탐색에서 내가 고른 수 (상대 차례의 키에 더한다)
+
+	pos   [zPlayers][bbBits]uint64
+	bombs [zPlayers][zStat]uint64
+	rng   [zPlayers][zStat]uint64
+	boxes [zPlayers][zBoxes]uint64
+	dead  [zPlayers]uint64
+}
+
+// 키는 실행할 때마다 같아야 탐색 결과를 다시 만들어볼 수 있다.
+func init() {
+	r := rand.New(rand.NewSource(0x5eed))
+	fill := func(keys []uint64) {
+		for i := range keys {
+			keys[i] = r.Uint64()
+		}
+	}
+	z := &zobrist
+	for i := 0; i < bbBits; i++ {
+		fill(z.box[i][:])
+		fill(z.item[i][:])
+		fill(z.bomb[i][:])
+		fill(z.owner[i][:])
+		fill(z.brange[i][:])
+		fill(z.act[i][:])
+	}
+	for id := 0; id < zPlayers; id++ {
+		fill(z.pos[id][:])
+		fill(z.bombs[id][:])
+		fill(z.rng[id][:])
+		fill(z.boxes[id][:])
+	}
+	fill(z.dead[:])
+}
+
+func cellKey(p Pos, c int) uint64 {
+	switch c {
+	case cellBoxEmpty, cellBoxRange, cellBoxPlus:
+		return zobrist.box[geo.index(p)][c-cellBoxEmpty]
+	}
+	return 0
+}
+
+func bombKey(b Bomb) uint64 {
+	i := geo.index(b.Pos)
+	return zobrist.bomb[i][b.CountDown&(zTimer-1)] ^
+		zobrist.owner[i][b.Owner&(zPlayers-1)] ^
+		zobrist.brange[i][b.Range&(zStat-1)]
+}
+
+func itemKey(it Item) uint64 {
+	return zobrist.item[geo.index(it.Pos)][it.Type%3]
+}
+
+func actionKey(a action) uint64 {
+	if a.bomb {
+		return zobrist.act[geo.index(a.pos)][1]
+	}
+	return zobrist.act[geo.index(a.pos)][0]
+}
+
+// playerKey 는 players[i] 의 위치와 능력치, 부순 상자 수, 죽었는지
+func (w *world) playerKey(i int) uint64 {
+	p := w.players[i]
+	id := p.ID & (zPlayers - 1)
+	k := zobrist.pos[id][geo.index(p.Pos)] ^
+		zobrist.bombs[id][p.Bombs&(zStat-1)] ^
+		zobrist.rng[id][p.Range&(zStat-1)] ^
+		zobrist.boxes[id][w.boxes[i]&(zBoxes-1)]
+	if !w.alive(i) {
+		k ^= zobrist.dead[id]
+	}
+	return k
+}
+
+// rehash 는 w.hash 를 처음부터 다시 구한다.
+func (w *world) rehash() uint64 {
+	var h uint64
+	for y, row := range w.board {
+		for x, c := range row {
+			h ^= cellKey(Pos{x, y}, c)
+		}
+	}
+	for i := range w.players {
+		h ^= w.playerKey(i)
+	}
+	for _, b := range w.bombs {
+		h ^= bombKey(b)
+	}
+	for _, it := range w.items {
+		h ^= itemKey(it)
+	}
+	return h
+}
@@ -0,0 +1,44 @@
+//go:build !arena
+
+package main
+
+import "testing"
+
+// TestIncrementalHash 는 step 이 조금씩 고친 hash 가 처음부터 다시 구한 것과 같은지 본다.
+func TestIncrementalHash(t *testing.T) {
+	worldGames(t, 200, func(rf *referee, w *world, acts []action, cmds []string) {
+		if w.hash != w.rehash() {
+			t.Fatalf("turn %d: newWorld hash %x, rehash %x", rf.turn, w.hash, w.rehash())
+		}
+		w.step(acts)
+		if w.hash != w.rehash() {
+			t.Fatalf("turn %d: hash %x after step %v, rehash %x\n%v", rf.turn, w.hash, acts, w.rehash(), w)
+		}
+	})
+}
+
+// TestHashDistinguishes 는 상태가 다르면 hash 도 다른지 몇 가지 바꿔서 본다.
+func TestHashDistinguishes(t *testing.T) {
+	openState(5, 5, Player{Pos{0, 0}, 0, 1, 3}, Player{Pos{4, 4}, 1, 1, 3}).apply()
+	tests := []struct {
+		name   string
+		change func(w *world)
+	}{
+		{"move", func(w *world) { w.players[0].Pos = Pos{1, 0} }},
+		{"bombs", func(w *world) { w.players[0].Bombs = 2 }},
+		{"range", func(w *world) { w.players[1].Range = 4 }},
+		{"box", func(w *world) { w.board[2][2] = cellBoxEmpty }},
+		{"item", func(w *world) { w.items = append(w.items, Item{Pos{2, 2}, itemExtraBomb}) }},
+		{"bomb", func(w *world) { w.bombs = append(w.bombs, Bomb{Pos: Pos{2, 2}, Owner: 0, CountDown: 3, Range: 3}) }},
+		{"boxes", func(w *world) { w.boxes[1] = 1 }},
+		{"dead", func(w *world) { w.dead[1] = 1 }},
+	}
+	base := newWorld().hash
+	for _, tt := range tests {
+		w := newWorld()
+		tt.change(w)
+		if h := w.rehash(); h == base {
+			t.Errorf("%s: hash unchanged", tt.name)
+		}
+	}
+}